@@ -2,6 +2,7 @@ package classify
 
 import (
 	"fmt"
+	"math"
 	"path"
 	"strings"
 	"time"
@@ -29,34 +30,66 @@ const (
 	StrContains             = 20
 	StrSimilar              = 10
 	NoMatch                 = 0
+	FrequencyFactor         = 4 // points per doubling of the visit count
+	ParentDivisor           = 5 // discount of parent path-segment matches
 )
 
 // Rating of a search query
 type Rating struct {
 	TimePoints       uint
 	SimilarityPoints uint
+	FrequencyPoints  uint
+	ExtraPoints      uint // e.g. set by an external ranker
+	// Depth of the matched path segment, 0 is the base name and 1
+	// its parent. Results are sorted by depth before their points.
+	Depth uint
 }
 
 // Points return the point sum of a rateing.
 // If no similarity is found, time points are ignored.
 func (r *Rating) Points() uint {
-	return r.SimilarityPoints + r.TimePoints
+	return r.SimilarityPoints + r.TimePoints + r.FrequencyPoints +
+		r.ExtraPoints
 }
 
-// NewRating rates search-term s for path p within time-slice a.
-func NewRating(s, p string, a ...time.Time) (*Rating, error) {
-	base := path.Base(p)
-	n := classifyText(base, s)
+// add the points of o to r.
+func (r *Rating) add(o *Rating) {
+	r.TimePoints += o.TimePoints
+	r.SimilarityPoints += o.SimilarityPoints
+	r.FrequencyPoints += o.FrequencyPoints
+	r.ExtraPoints += o.ExtraPoints
+	if o.Depth > r.Depth {
+		r.Depth = o.Depth
+	}
+}
+
+// NewRating rates search-term s for path p, visited count times,
+// within time-slice a, relative to now.
+func NewRating(s, p string, count uint32, now time.Time,
+	a ...time.Time) (*Rating, error) {
+	n, depth := classifyPath(p, s)
 	if n == NoMatch {
 		return nil, fmt.Errorf("NewRating - similarity: noMatch")
 	}
-	timeRate := classifyTime(a...)
-	return &Rating{SimilarityPoints: n, TimePoints: timeRate}, nil
+	return &Rating{
+		SimilarityPoints: n,
+		Depth:            depth,
+		TimePoints:       classifyTime(now, a...),
+		FrequencyPoints:  classifyFrequency(count),
+	}, nil
 }
 
-func classifyTime(a ...time.Time) uint {
+// classifyFrequency rates the visit count on a logarithmic scale,
+// a folder visited once gets no points.
+func classifyFrequency(count uint32) uint {
+	if count < 2 {
+		return 0
+	}
+	return uint(math.Log2(float64(count)) * FrequencyFactor)
+}
+
+func classifyTime(now time.Time, a ...time.Time) uint {
 	var n uint
-	now := time.Now()
 	for _, t := range a {
 		n += timeHelper(now, t)
 	}
@@ -122,6 +155,33 @@ func timeHelper(now, t time.Time) uint {
 	return TimeOlderThanAYear
 }
 
+// classifyPath compares all segments of path p to the query string
+// and returns the points and depth of the best matching segment.
+// A base name match gets full points, a parent segment match is
+// divided by ParentDivisor times its distance to the base name plus one.
+func classifyPath(p, query string) (uint, uint) {
+	a := strings.Split(path.Clean(p), "/")
+	last := len(a) - 1
+	if n := classifyText(a[last], query); n != NoMatch {
+		return n, 0
+	}
+	var best, depth uint
+	for d := 1; d <= last; d++ {
+		seg := a[last-d]
+		if seg == "" {
+			continue
+		}
+		n := classifyText(seg, query) / (ParentDivisor * uint(d+1))
+		if n > best {
+			best, depth = n, uint(d)
+		}
+	}
+	return best, depth
+}
+
+// Text rates how similar the path segment name is to query.
+func Text(name, query string) uint { return classifyText(name, query) }
+
 // classifyText compares base to the query sting.
 func classifyText(base, query string) uint {
 	// remove leading dot from base if not found in query
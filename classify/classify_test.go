@@ -174,3 +174,53 @@ func TestTimeHelper(t *testing.T) {
 // func random(min, max int) int {
 // 	return rand.Intn(max-min) + min
 // }
+
+func TestClassifyFrequency(t *testing.T) {
+	tt := []struct {
+		name  string
+		count uint32
+		exp   uint
+	}{
+		{name: "never updated", count: 0, exp: 0},
+		{name: "visited once", count: 1, exp: 0},
+		{name: "visited twice", count: 2, exp: FrequencyFactor},
+		{name: "visited 2000 times", count: 2000, exp: 43},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if n := classifyFrequency(tc.count); n != tc.exp {
+				t.Errorf("%s - exp %v, got %v", tc.name, tc.exp, n)
+			}
+		})
+	}
+}
+
+func TestClassifyPath(t *testing.T) {
+	tt := []struct {
+		name, path, query string
+		exp, depth        uint
+	}{
+		{name: "base name", path: "/srv/backend", query: "backend",
+			exp: StrEquals},
+		{name: "parent", path: "/srv/backend/current", query: "backend",
+			exp: StrEquals / (ParentDivisor * 2), depth: 1},
+		{name: "grandparent", path: "/srv/backend/current/log",
+			query: "backend", exp: StrEquals / (ParentDivisor * 3), depth: 2},
+		{name: "base name wins", path: "/srv/backend/backend-old",
+			query: "backend", exp: StrStartsWith},
+		{name: "best parent wins", path: "/backend/xbackend/current",
+			query: "backend", exp: StrEndsWith / (ParentDivisor * 2), depth: 1},
+		{name: "no match", path: "/srv/frontend/current", query: "zot",
+			exp: NoMatch},
+		{name: "root", path: "/", query: "foo", exp: NoMatch},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			n, depth := classifyPath(tc.path, tc.query)
+			if n != tc.exp || depth != tc.depth {
+				t.Errorf("%s - exp %v at depth %v, got %v at %v", tc.name,
+					tc.exp, tc.depth, n, depth)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package classify
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thibran/maybe/rated/folder"
+)
+
+// Context of a rating.
+type Context struct {
+	Now time.Time // reference time, time.Now() if zero
+}
+
+func (c Context) now() time.Time {
+	if c.Now.IsZero() {
+		return time.Now()
+	}
+	return c.Now
+}
+
+// Ranker rates folder f for query. An error is returned when
+// f does not match query.
+type Ranker interface {
+	Rate(f *folder.Folder, query string, c Context) (*Rating, error)
+}
+
+// BatchRanker rates all folders fs at once. The returned slice has the
+// length of fs, nil entries did not match.
+type BatchRanker interface {
+	RateAll(fs []*folder.Folder, query string, c Context) ([]*Rating, error)
+}
+
+// Default ranker.
+var Default Ranker = Bucket{}
+
+// Bucket ranker rates similarity, time and frequency in fixed buckets.
+type Bucket struct{}
+
+// Rate implementation for Bucket.
+func (Bucket) Rate(f *folder.Folder, query string, c Context) (*Rating, error) {
+	return NewRating(query, f.Path, f.UpdateCount, c.now(), f.Times...)
+}
+
+// Similarity ranker rates only the path similarity.
+type Similarity struct{}
+
+// Rate implementation for Similarity.
+func (Similarity) Rate(f *folder.Folder, query string, c Context) (*Rating, error) {
+	n, depth := classifyPath(f.Path, query)
+	if n == NoMatch {
+		return nil, fmt.Errorf("Similarity - noMatch")
+	}
+	return &Rating{SimilarityPoints: n, Depth: depth}, nil
+}
+
+// Time ranker rates only the visit times, it matches every folder.
+type Time struct{}
+
+// Rate implementation for Time.
+func (Time) Rate(f *folder.Folder, query string, c Context) (*Rating, error) {
+	return &Rating{TimePoints: classifyTime(c.now(), f.Times...)}, nil
+}
+
+// Frequency ranker rates only the visit count, it matches every folder.
+type Frequency struct{}
+
+// Rate implementation for Frequency.
+func (Frequency) Rate(f *folder.Folder, query string, c Context) (*Rating, error) {
+	return &Rating{FrequencyPoints: classifyFrequency(f.UpdateCount)}, nil
+}
+
+// Compose rankers, a folder must match all of them.
+// The points of all ratings are summed up.
+type Compose []Ranker
+
+// Rate implementation for Compose.
+func (a Compose) Rate(f *folder.Folder, query string, c Context) (*Rating, error) {
+	res, err := a.RateAll([]*folder.Folder{f}, query, c)
+	if err != nil {
+		return nil, err
+	}
+	if res[0] == nil {
+		return nil, fmt.Errorf("Compose - noMatch")
+	}
+	return res[0], nil
+}
+
+// RateAll implementation for Compose.
+func (a Compose) RateAll(fs []*folder.Folder, query string,
+	c Context) ([]*Rating, error) {
+	res := make([]*Rating, len(fs))
+	for i := range res {
+		res[i] = &Rating{}
+	}
+	for _, rk := range a {
+		ratings, err := rateAll(rk, fs, query, c)
+		if err != nil {
+			return nil, err
+		}
+		for i, r := range ratings {
+			if res[i] == nil {
+				continue
+			}
+			if r == nil {
+				res[i] = nil
+				continue
+			}
+			res[i].add(r)
+		}
+	}
+	return res, nil
+}
+
+func rateAll(rk Ranker, fs []*folder.Folder, query string,
+	c Context) ([]*Rating, error) {
+	if br, ok := rk.(BatchRanker); ok {
+		return br.RateAll(fs, query, c)
+	}
+	res := make([]*Rating, len(fs))
+	for i, f := range fs {
+		if r, err := rk.Rate(f, query, c); err == nil {
+			res[i] = r
+		}
+	}
+	return res, nil
+}
+
+// Command is an external ranker process. The query is written as
+// first line to its stdin, followed by one candidate per line:
+//
+//	<update-count>\t<last-visit-unix-time>\t<path>
+//
+// For every candidate, in the same order, the process must print one
+// line to stdout: a non-negative integer score, or "-" for no match.
+// The score is stored as Rating.ExtraPoints.
+type Command struct {
+	Name string
+	Args []string
+}
+
+// Rate implementation for Command.
+func (cmd Command) Rate(f *folder.Folder, query string, c Context) (*Rating, error) {
+	res, err := cmd.RateAll([]*folder.Folder{f}, query, c)
+	if err != nil {
+		return nil, err
+	}
+	if res[0] == nil {
+		return nil, fmt.Errorf("Command - noMatch")
+	}
+	return res[0], nil
+}
+
+// RateAll implementation for Command.
+func (cmd Command) RateAll(fs []*folder.Folder, query string,
+	c Context) ([]*Rating, error) {
+	var in bytes.Buffer
+	fmt.Fprintln(&in, query)
+	for _, f := range fs {
+		var last int64
+		if len(f.Times) > 0 {
+			last = f.Times[0].Unix()
+		}
+		fmt.Fprintf(&in, "%d\t%d\t%s\n", f.UpdateCount, last, f.Path)
+	}
+	ec := exec.Command(cmd.Name, cmd.Args...)
+	ec.Stdin = &in
+	ec.Stderr = os.Stderr
+	ec.Env = append(os.Environ(),
+		"MAYBE_QUERY="+query,
+		fmt.Sprintf("MAYBE_NOW=%d", c.now().Unix()))
+	out, err := ec.Output()
+	if err != nil {
+		return nil, fmt.Errorf("Command - %s: %v", cmd.Name, err)
+	}
+	res := make([]*Rating, 0, len(fs))
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		s := strings.TrimSpace(sc.Text())
+		if s == "-" {
+			res = append(res, nil)
+			continue
+		}
+		n, err := strconv.ParseUint(s, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("Command - %s: invalid score %q",
+				cmd.Name, s)
+		}
+		res = append(res, &Rating{ExtraPoints: uint(n)})
+	}
+	if len(res) != len(fs) {
+		return nil, fmt.Errorf("Command - %s: expected %d scores, got %d",
+			cmd.Name, len(fs), len(res))
+	}
+	return res, nil
+}
+
+// Parse a comma separated ranker configuration. Known rankers are:
+// default, similarity, time, frequency and exec:<command> [args...].
+// Multiple rankers are composed.
+func Parse(spec string) (Ranker, error) {
+	var a Compose
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		switch {
+		case s == "default" || s == "bucket":
+			a = append(a, Bucket{})
+		case s == "similarity":
+			a = append(a, Similarity{})
+		case s == "time":
+			a = append(a, Time{})
+		case s == "frequency":
+			a = append(a, Frequency{})
+		case strings.HasPrefix(s, "exec:"):
+			args := strings.Fields(strings.TrimPrefix(s, "exec:"))
+			if len(args) == 0 {
+				return nil, fmt.Errorf("ranker %q - command missing", s)
+			}
+			a = append(a, Command{Name: args[0], Args: args[1:]})
+		default:
+			return nil, fmt.Errorf("unknown ranker %q", s)
+		}
+	}
+	if len(a) == 1 {
+		return a[0], nil
+	}
+	return a, nil
+}
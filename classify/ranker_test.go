@@ -0,0 +1,88 @@
+package classify
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/thibran/maybe/rated/folder"
+)
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		name, spec string
+		exp        Ranker
+		err        bool
+	}{
+		{name: "default", spec: "default", exp: Bucket{}},
+		{name: "compose", spec: "similarity, time",
+			exp: Compose{Similarity{}, Time{}}},
+		{name: "exec", spec: "exec:rank -x",
+			exp: Command{Name: "rank", Args: []string{"-x"}}},
+		{name: "exec without command", spec: "exec:", err: true},
+		{name: "unknown", spec: "default,zot", err: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rk, err := Parse(tc.spec)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("%s - should fail", tc.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s1, s2 := fmtRanker(rk), fmtRanker(tc.exp); s1 != s2 {
+				t.Errorf("%s - exp %s, got %s", tc.name, s2, s1)
+			}
+		})
+	}
+}
+
+func TestCompose(t *testing.T) {
+	now := time.Now()
+	c := Context{Now: now}
+	f := folder.New("/home/foo", now)
+	f.UpdateCount = 4
+	r, err := Compose{Similarity{}, Time{}, Frequency{}}.Rate(f, "foo", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Bucket{}.Rate(f, "foo", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *r != *b {
+		t.Fatalf("exp %+v, got %+v", *b, *r)
+	}
+	if _, err := (Compose{Time{}, Similarity{}}).Rate(f, "zzz", c); err == nil {
+		t.Fatal("all rankers should have to match")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	script := `read q; while read n last p; do
+	if [ "$p" = /home/foo ]; then echo $n; else echo -; fi; done`
+	cmd := Command{Name: "sh", Args: []string{"-c", script}}
+	now := time.Now()
+	f1 := folder.New("/home/foo", now)
+	f1.UpdateCount = 7
+	f2 := folder.New("/home/bar", now)
+	a, err := cmd.RateAll([]*folder.Folder{f1, f2}, "foo", Context{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 2 || a[0] == nil || a[0].ExtraPoints != 7 || a[1] != nil {
+		t.Fatalf("unexpected ratings: %v", a)
+	}
+}
+
+func fmtRanker(rk Ranker) string {
+	return fmt.Sprintf("%#v", rk)
+}
@@ -0,0 +1,150 @@
+// Package evaluate replays recorded visits and search queries to
+// compare the result quality of rankers.
+package evaluate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thibran/maybe/classify"
+	"github.com/thibran/maybe/pref"
+	"github.com/thibran/maybe/repo"
+)
+
+// Event kinds of a visit log.
+const (
+	Add    = "add"
+	Search = "search"
+)
+
+// Event of a visit log.
+type Event struct {
+	Time time.Time
+	Kind string // Add or Search
+	Arg  string // path or query
+}
+
+// Result of an evaluation run.
+type Result struct {
+	Ranker   string
+	Searches int           // searches followed by a visit
+	Top1     float64       // share of searches with the visit ranked first
+	Top3     float64       // share of searches with the visit in the top three
+	MRR      float64       // mean reciprocal rank
+	Latency  time.Duration // mean search duration
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%s\t%d\t%.3f\t%.3f\t%.3f\t%v", r.Ranker,
+		r.Searches, r.Top1, r.Top3, r.MRR, r.Latency)
+}
+
+// Parse a visit log. Every non-empty line, not starting with #, has
+// the form:
+//
+//	<unix-time|RFC3339> add <path>
+//	<unix-time|RFC3339> search [<start>] <query>
+func Parse(r io.Reader) ([]Event, error) {
+	var a []Event
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected <time> <kind> <arg>", n)
+		}
+		t, err := parseTime(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		kind := fields[1]
+		if kind != Add && kind != Search {
+			return nil, fmt.Errorf("line %d: unknown kind %q", n, kind)
+		}
+		a = append(a, Event{Time: t, Kind: kind,
+			Arg: strings.TrimSpace(fields[2])})
+	}
+	return a, sc.Err()
+}
+
+func parseTime(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// Run replays events against an empty in-memory repo, rated by the
+// ranker configuration spec. The clock is pinned to the time of the
+// current event. A search is expected to find the path of the next
+// add event, searches without following add event are ignored.
+func Run(events []Event, spec string, maxEntries int) (Result, error) {
+	res := Result{Ranker: spec}
+	rk, err := classify.Parse(spec)
+	if err != nil {
+		return res, err
+	}
+	var now time.Time
+	r := repo.New("", maxEntries)
+	r.SetRanker(rk)
+	r.SetClock(func() time.Time { return now })
+
+	var latency time.Duration
+	var top1, top3 int
+	var rr float64
+	for i, e := range events {
+		now = e.Time
+		if e.Kind == Add {
+			r.Add(e.Arg, e.Time)
+			continue
+		}
+		exp, ok := nextVisit(events[i+1:])
+		if !ok {
+			continue
+		}
+		start := time.Now()
+		a, err := r.List(pref.NewQuery(e.Arg))
+		if err != nil {
+			return res, err
+		}
+		latency += time.Since(start)
+		res.Searches++
+		for k, rf := range a {
+			if rf.Path != exp {
+				continue
+			}
+			if k == 0 {
+				top1++
+			}
+			if k < 3 {
+				top3++
+			}
+			rr += 1 / float64(k+1)
+			break
+		}
+	}
+	if res.Searches == 0 {
+		return res, nil
+	}
+	n := float64(res.Searches)
+	res.Top1 = float64(top1) / n
+	res.Top3 = float64(top3) / n
+	res.MRR = rr / n
+	res.Latency = latency / time.Duration(res.Searches)
+	return res, nil
+}
+
+// nextVisit returns the path of the directly following add event.
+func nextVisit(events []Event) (string, bool) {
+	if len(events) == 0 || events[0].Kind != Add {
+		return "", false
+	}
+	return events[0].Arg, true
+}
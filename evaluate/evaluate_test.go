@@ -0,0 +1,56 @@
+package evaluate
+
+import (
+	"strings"
+	"testing"
+)
+
+const visitLog = `# recorded visits
+1500000000 add /home/tux/src/foo
+1500000100 add /home/tux/foo
+1500000200 add /home/tux/src/foo
+1500000300 search foo
+1500000310 add /home/tux/src/foo
+1500000400 search tux foo
+1500000410 add /home/tux/foo
+2017-07-14T02:50:00Z search bar
+`
+
+func TestParse(t *testing.T) {
+	a, err := Parse(strings.NewReader(visitLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 8 {
+		t.Fatalf("exp 8 events, got %d", len(a))
+	}
+	if a[4].Kind != Add || a[4].Arg != "/home/tux/src/foo" {
+		t.Fatalf("unexpected event %+v", a[4])
+	}
+	if _, err := Parse(strings.NewReader("123 jump /foo")); err == nil {
+		t.Fatal("unknown kind should fail")
+	}
+	if _, err := Parse(strings.NewReader("yesterday add /foo")); err == nil {
+		t.Fatal("invalid time should fail")
+	}
+}
+
+func TestRun(t *testing.T) {
+	a, err := Parse(strings.NewReader(visitLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Run(a, "default", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Searches != 2 {
+		t.Fatalf("exp 2 searches, got %d", res.Searches)
+	}
+	if res.Top1 != 0.5 || res.Top3 != 1 || res.MRR != 0.75 {
+		t.Fatalf("unexpected result: %s", res)
+	}
+	if _, err := Run(a, "zot", 100); err == nil {
+		t.Fatal("unknown ranker should fail")
+	}
+}
@@ -5,52 +5,107 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/thibran/maybe/classify"
+	"github.com/thibran/maybe/evaluate"
+	"github.com/thibran/maybe/output"
+	"github.com/thibran/maybe/picker"
 	"github.com/thibran/maybe/pref"
 	"github.com/thibran/maybe/rated"
 	"github.com/thibran/maybe/rated/folder"
 	"github.com/thibran/maybe/repo"
+	"github.com/thibran/maybe/resolve"
+	"github.com/thibran/maybe/session"
+	"github.com/thibran/maybe/shell"
 	"github.com/thibran/maybe/util"
 )
 
-const appVersion = "0.5.0"
+const (
+	appVersion = "0.5.0"
+	pickLimit  = 200 // max. candidates shown by the picker
+	// max. candidates printed by -complete
+	completeLimit = 20
+)
+
+// Exit statuses of a failed search.
+const (
+	exitNoResult  = 1
+	exitAmbiguous = 3
+	// the external ranker failed
+	exitRankerFailed = 4
+	// exec exits with the status of the command, so a failed lookup
+	// has a status shells don't use otherwise
+	exitExecLookup = 125
+)
 
 func main() {
 	p := pref.Parse()
 	r := repo.New(filepath.Join(p.DataDir, "maybe.data"), p.MaxEntries)
 	r.Load(p.DataDir)
-	// version
-	if p.Version {
-		handleVersion(r, p.DataDir)
-		return
-	}
-	// init
-	if p.Init {
-		handleInit(r, p.HomeDir)
-		return
+	rk, err := classify.Parse(p.Ranker)
+	if err != nil {
+		log.Fatalf("ranker: %v\n", err)
 	}
-	// add path
-	if p.Add != "" {
-		handleAdd(r, p.Add)
-		return
+	r.SetRanker(rk)
+	if !output.IsColorMode(p.Color) {
+		log.Fatalf("unknown color mode: %q\n", p.Color)
 	}
-	// search
-	if p.Search.IsNotEmpty() {
-		handleSearch(r, p.Search)
-		return
+	if !output.IsColorMode(p.Hyperlink) {
+		log.Fatalf("unknown hyperlink mode: %q\n", p.Hyperlink)
 	}
-	// list
-	if p.List.IsNotEmpty() {
-		handleList(r, p.List)
-		return
+	switch p.Command {
+	case pref.CmdVersion:
+		handleVersion(r, p.DataDir)
+	case pref.CmdEvaluate:
+		handleEvaluate(p.Evaluate, p.Ranker, p.MaxEntries, p.Args)
+	case pref.CmdComplete:
+		handleComplete(r, p.Complete)
+	case pref.CmdInit:
+		if p.InitShell != "" {
+			handleShellInit(p)
+			return
+		}
+		handleInit(r, p.HomeDir)
+	case pref.CmdAdd:
+		handleAdd(r, p.Add, p.AutoPruneAfter)
+	case pref.CmdRemove, pref.CmdRemoveTree:
+		handleRemove(r, p.Remove, p.Command == pref.CmdRemoveTree)
+	case pref.CmdForget:
+		handleForget(r, p.Forget)
+	case pref.CmdPrune:
+		handlePrune(r, p.PruneAfter, p.DryRun)
+	case pref.CmdPin:
+		handlePin(r, p.Pin, p.PinPath)
+	case pref.CmdUnpin:
+		handleUnpin(r, p.Pin)
+	case pref.CmdPins:
+		for _, pin := range r.Pins() {
+			fmt.Printf("%s\t%s\n", pin.Alias, pin.Path)
+		}
+	case pref.CmdStats:
+		s := r.Stats(folder.CheckerFn(), p.Limit)
+		writeOutput(output.WriteStats(os.Stdout, p.Format, s))
+	case pref.CmdSearch:
+		handleSearch(r, p)
+	case pref.CmdList:
+		handleList(r, p)
+	case pref.CmdExec:
+		handleExec(r, p)
+	case pref.CmdSelect:
+		handleSelect(p.DataDir, p.Select)
+	case pref.CmdPick:
+		handlePick(r, p.Pick)
+	default:
+		pref.Usage()
+		os.Exit(1)
 	}
-	fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-	flag.PrintDefaults()
-	os.Exit(1)
 }
 
 func handleVersion(r *repo.Repo, dataDir string) {
@@ -62,6 +117,29 @@ func handleVersion(r *repo.Repo, dataDir string) {
 	}
 }
 
+func handleEvaluate(visitLog, ranker string, maxEntries int, rankers []string) {
+	f, err := os.Open(visitLog)
+	if err != nil {
+		log.Fatalf("handleEvaluate - %v\n", err)
+	}
+	defer f.Close()
+	events, err := evaluate.Parse(f)
+	if err != nil {
+		log.Fatalf("handleEvaluate - %s: %v\n", visitLog, err)
+	}
+	if len(rankers) == 0 {
+		rankers = []string{ranker}
+	}
+	fmt.Println("Ranker\tSearches\tTop1\tTop3\tMRR\tLatency")
+	for _, spec := range rankers {
+		res, err := evaluate.Run(events, spec, maxEntries)
+		if err != nil {
+			log.Fatalf("handleEvaluate - %v\n", err)
+		}
+		fmt.Println(res)
+	}
+}
+
 func handleInit(r *repo.Repo, homeDir string) {
 	r.Walk(homeDir)
 	if err := r.Save(); err != nil {
@@ -70,63 +148,545 @@ func handleInit(r *repo.Repo, homeDir string) {
 	fmt.Println("entries:", r.Size())
 }
 
-func handleAdd(r *repo.Repo, path string) {
+func handleShellInit(p pref.Pref) {
+	o := shell.Options{Cmd: p.ShellCmd, ListCmd: p.ShellListCmd,
+		Hook: p.ShellHook, Completion: p.ShellCompletion}
+	completionOptions(&o)
+	if err := shell.Init(os.Stdout, p.InitShell, o); err != nil {
+		log.Fatalf("init: %v\n", err)
+	}
+}
+
+// completionOptions sets the flags and commands of maybe in o,
+// with the kind of their argument.
+func completionOptions(o *shell.Options) {
+	modes := []string{output.ColorAuto, output.ColorAlways, output.ColorNever}
+	args := map[string]shell.Flag{
+		"add":         {Arg: shell.ArgDir},
+		"datadir":     {Arg: shell.ArgDir},
+		"remove-tree": {Arg: shell.ArgDir},
+		"evaluate":    {Arg: shell.ArgFile},
+		"search":      {Arg: shell.ArgFolder},
+		"list":        {Arg: shell.ArgFolder},
+		"pick":        {Arg: shell.ArgFolder},
+		"exec":        {Arg: shell.ArgFolder},
+		"forget":      {Arg: shell.ArgFolder},
+		"remove":      {Arg: shell.ArgDir},
+		"format": {Arg: shell.ArgValues, Values: []string{output.Text,
+			output.JSON, output.TSV, output.NUL}},
+		"color":     {Arg: shell.ArgValues, Values: modes},
+		"hyperlink": {Arg: shell.ArgValues, Values: modes},
+		"template":  {Arg: shell.ArgValues, Values: output.PresetNames()},
+		"ranker": {Arg: shell.ArgValues, Values: []string{"default",
+			"similarity", "time", "frequency"}},
+	}
+	o.Flags = nil
+	pref.LegacyFlags().VisitAll(func(f *flag.Flag) {
+		sf, ok := args[f.Name]
+		if !ok {
+			sf.Arg = shell.ArgAny
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok &&
+				b.IsBoolFlag() {
+				sf.Arg = shell.ArgNone
+			}
+		}
+		sf.Name, sf.Usage = f.Name, f.Usage
+		if sf.Usage == "" {
+			sf.Usage = f.Name
+		}
+		o.Flags = append(o.Flags, sf)
+	})
+	var names []string
+	for _, c := range pref.Commands() {
+		names = append(names, c.Name)
+	}
+	o.Commands = nil
+	for _, c := range pref.Commands() {
+		sf, ok := args[c.Name]
+		switch {
+		case c.Name == pref.CmdInit:
+			sf = shell.Flag{Arg: shell.ArgValues, Values: shell.Names()}
+		case c.Name == pref.CmdHelp:
+			sf = shell.Flag{Arg: shell.ArgValues, Values: names}
+		case !ok && c.Args == "":
+			sf.Arg = shell.ArgNone
+		case !ok:
+			sf.Arg = shell.ArgAny
+		}
+		sf.Name, sf.Usage = c.Name, c.Usage
+		o.Commands = append(o.Commands, sf)
+	}
+}
+
+func handleComplete(r *repo.Repo, q pref.Query) {
+	q.Start = strings.TrimSpace(q.Start)
+	if strings.TrimSpace(q.Last) == "" && q.Start == "" {
+		return
+	}
+	// an empty partial matches all folders below start, including
+	// deeper ones, so fetch more to find the direct children
+	n := completeLimit
+	if q.Last == "" {
+		n = pickLimit
+	}
+	a, err := r.Candidates(folder.CheckerFn(), shell.Query(q.Start, q.Last), n)
+	if err != nil {
+		rankerFailed(err)
+	}
+	c := shell.Candidates(a, q.Start, q.Last)
+	if len(c) > completeLimit {
+		c = c[:completeLimit]
+	}
+	writeOutput(shell.WriteCandidates(os.Stdout, c))
+}
+
+// handleAdd adds path to the index, with a positive pruneAfter the
+// index is pruned once a day.
+func handleAdd(r *repo.Repo, path string, pruneAfter time.Duration) {
 	if strings.TrimSpace(path) == "" {
 		return
 	}
 	r.Add(path, time.Now())
+	r.AutoPrune(folder.CheckerFn(), pruneAfter)
 	if err := r.Save(); err != nil {
 		log.Fatalf("handleAdd - path: %s\n", err)
 	}
 }
 
-func handleSearch(r *repo.Repo, q pref.Query) {
-	// return path-query directly
-	if q.Start == "" && strings.HasPrefix(q.Last, "/") {
-		fmt.Println(q.Last)
+// handleRemove removes path, or with tree also all folders below it,
+// from the index. Exits with status 1 if path is unknown.
+func handleRemove(r *repo.Repo, path string, tree bool) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		log.Fatalf("handleRemove - %v\n", err)
+	}
+	var n int
+	if tree {
+		n = r.RemoveTree(path)
+	} else if r.Remove(path) {
+		n = 1
+	}
+	if n == 0 {
+		fmt.Fprintf(os.Stderr, "not in index: %s\n", path)
+		os.Exit(1)
+	}
+	if err := r.Save(); err != nil {
+		log.Fatalf("handleRemove - %v\n", err)
+	}
+	util.Logf("removed %d folders\n", n)
+}
+
+// handleForget lets the user choose results of q to remove from
+// the index, missing folders included.
+func handleForget(r *repo.Repo, q pref.Query) {
+	a, err := r.List(q)
+	if err != nil {
+		rankerFailed(err)
+	}
+	if len(a) == 0 {
+		os.Exit(1)
+	}
+	if len(a) > pickLimit {
+		a = a[:pickLimit]
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		log.Fatalf("handleForget - %v\n", err)
+	}
+	defer tty.Close()
+	var paths []string
+	for _, rf := range a {
+		paths = append(paths, rf.Path)
+	}
+	chosen, err := picker.Choose(tty, tty, "remove", paths)
+	if err == picker.ErrCanceled {
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	for _, path := range chosen {
+		r.Remove(path)
+	}
+	if err := r.Save(); err != nil {
+		log.Fatalf("handleForget - %v\n", err)
+	}
+}
+
+// handlePrune removes folders missing longer than after and prints
+// their paths, with dryRun nothing is removed.
+func handlePrune(r *repo.Repo, after time.Duration, dryRun bool) {
+	for _, path := range r.Prune(folder.CheckerFn(), after, dryRun) {
+		fmt.Println(path)
+	}
+	if dryRun {
 		return
 	}
-	rf, err := r.Search(folder.CheckerFn(), q)
+	if err := r.Save(); err != nil {
+		log.Fatalf("handlePrune - %v\n", err)
+	}
+}
+
+// handlePin pins the existing folder path with alias.
+func handlePin(r *repo.Repo, alias, path string) {
+	path, err := filepath.Abs(path)
 	if err != nil {
-		// all okay
-		if err == repo.ErrNoResult {
+		log.Fatalf("handlePin - %v\n", err)
+	}
+	if !folder.CheckerFn()(path) {
+		fmt.Fprintf(os.Stderr, "no directory: %s\n", path)
+		os.Exit(1)
+	}
+	r.Pin(alias, path)
+	if err := r.Save(); err != nil {
+		log.Fatalf("handlePin - %v\n", err)
+	}
+}
+
+// handleUnpin unpins the folder with alias or path s. Exits with
+// status 1 if none is pinned.
+func handleUnpin(r *repo.Repo, s string) {
+	if !r.Unpin(s) {
+		// s may be a relative path
+		abs, err := filepath.Abs(s)
+		if err != nil || !r.Unpin(abs) {
+			fmt.Fprintf(os.Stderr, "not pinned: %s\n", s)
 			os.Exit(1)
 		}
-		// hell should freez
-		util.Logln(err)
-		os.Exit(2)
 	}
-	fmt.Printf(rf.Path)
+	if err := r.Save(); err != nil {
+		log.Fatalf("handleUnpin - %v\n", err)
+	}
 }
 
-func handleList(r *repo.Repo, q pref.Query) {
-	a := r.List(q, true)
+func handleSearch(r *repo.Repo, p pref.Pref) {
+	path, rf, status := find(r, p, p.Search)
+	if status != 0 {
+		os.Exit(status)
+	}
+	if rf == nil {
+		printPath(path, p)
+		return
+	}
+	if p.Format != output.Text {
+		writeOutput(output.WriteOne(os.Stdout, p.Format,
+			output.NewEntry(rf, true)))
+		return
+	}
+	if p.Template != "" {
+		v := output.NewView(1, rf, rf.Path, true)
+		writeOutput(newTemplate(p.Template).ExecuteOne(os.Stdout, v))
+		return
+	}
+	fmt.Print(rf.Path)
+}
+
+// find the folder of search query q and, if taken from the index,
+// its rated entry. The status is 0, or exitNoResult if nothing is
+// found, exitAmbiguous if the result is too weak or ambiguous, or
+// exitRankerFailed if the ranker fails.
+func find(r *repo.Repo, p pref.Pref, q pref.Query) (string, *rated.Rated, int) {
+	// a bare number selects from the last list
+	if n, err := strconv.Atoi(q.Last); err == nil && q.Start == "" {
+		if path, err := session.Select(p.DataDir, session.ID(), n); err == nil {
+			return path, nil, 0
+		}
+	}
+	// a pinned alias wins over paths and ratings, e.g. w or w/src
+	if q.Start == "" {
+		alias, sub := q.Last, ""
+		if i := strings.Index(alias, "/"); i > 0 {
+			alias, sub = alias[:i], alias[i+1:]
+		}
+		if rf, ok := r.SearchPin(folder.CheckerFn(), alias); ok {
+			a := rated.Slice{rf}
+			if sub != "" {
+				a = descend(a, sub)
+			}
+			if len(a) == 0 {
+				return "", nil, exitNoResult
+			}
+			return a[0].Path, a[0], 0
+		}
+	}
+	// cd-style arguments, e.g. ~, .., - or $GOPATH/src
+	if q.Start == "" {
+		path, ok := resolve.Dir(q.Last, resolve.OSEnv())
+		// absolute paths are returned, even if missing
+		if !ok && strings.HasPrefix(q.Last, "/") {
+			path, ok = q.Last, true
+		}
+		if ok {
+			return path, nil, 0
+		}
+	}
+	// a trailing sub-path is looked up below the indexed folders
+	q, sub := resolve.SubPath(q)
+	// check only as many candidates for existence as needed: descend
+	// drops candidates and a too weak result prints all of them, an
+	// ambiguous one all inside the confidence window
+	n := pref.DefaultLimit
+	if sub == "" && p.MinScore == 0 {
+		n = 1
+		if p.Confidence > 0 {
+			n = 2
+		}
+	}
+	var a rated.Slice
+	for {
+		var err error
+		a, err = r.Candidates(folder.CheckerFn(), q, n)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return "", nil, exitRankerFailed
+		}
+		if p.Confidence == 0 || len(a) < n ||
+			a.Contenders(p.Confidence) < n {
+			break
+		}
+		n *= 2
+	}
+	if sub != "" {
+		a = descend(a, sub)
+	}
 	if len(a) == 0 {
+		return "", nil, exitNoResult
+	}
+	// too weak or ambiguous, let the caller choose
+	weak := a[0].Points() < p.MinScore
+	if weak || a.Ambiguous(p.Confidence) {
+		if !weak {
+			a = a[:a.Contenders(p.Confidence)]
+		}
+		for _, rf := range a {
+			fmt.Fprintf(os.Stderr, "%d\t%s\n", rf.Points(), rf.Path)
+		}
+		return "", nil, exitAmbiguous
+	}
+	return a[0].Path, a[0], 0
+}
+
+// handleExec runs the command line p.Args in the folder of the
+// query and exits with its exit status, or 128 plus the number of
+// the signal that killed it. Exits with status exitExecLookup if
+// the query has no result, 127 if the command can't be started.
+func handleExec(r *repo.Repo, p pref.Pref) {
+	dir, _, status := find(r, p, p.Exec)
+	if status != 0 {
+		os.Exit(exitExecLookup)
+	}
+	if p.Record {
+		handleAdd(r, dir, p.AutoPruneAfter)
+	}
+	cmd := exec.Command(p.Args[0], p.Args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := cmd.Run()
+	if e, ok := err.(*exec.ExitError); ok {
+		if ws, ok := e.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			os.Exit(128 + int(ws.Signal()))
+		}
+		os.Exit(e.ExitCode())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "maybe: %v\n", err)
+		os.Exit(127)
+	}
+}
+
+// descend into the sub-path sub below every entry of a, entries
+// without a matching sub-path are dropped.
+func descend(a rated.Slice, sub string) rated.Slice {
+	var res rated.Slice
+	for _, rf := range a {
+		path, ok := resolve.Descend(rf.Path, sub)
+		if !ok {
+			continue
+		}
+		f := *rf.Folder
+		f.Path = path
+		c := *rf
+		c.Folder = &f
+		res = append(res, &c)
+	}
+	return res
+}
+
+// printPath of a resolved search, not taken from the index.
+func printPath(path string, p pref.Pref) {
+	if p.Format == output.Text && p.Template == "" {
+		fmt.Print(path)
+		return
+	}
+	if p.Format == output.Text {
+		v := output.View{Path: path, DisplayPath: path,
+			Suffix: filepath.Base(path), Exists: folder.CheckerFn()(path)}
+		writeOutput(newTemplate(p.Template).ExecuteOne(os.Stdout, v))
 		return
 	}
-	var res []string
-	res = append(res, util.NormalOrVerbose("Rating\tFolder", "Time\tText\tFolder"))
+	e := output.Entry{Path: path, Exists: folder.CheckerFn()(path)}
+	writeOutput(output.WriteOne(os.Stdout, p.Format, e))
+}
+
+func handleList(r *repo.Repo, p pref.Pref) {
+	a, err := r.List(p.List)
+	if err != nil {
+		rankerFailed(err)
+	}
+	// listed holds all existing results up to offset+limit
+	var listed rated.Slice
 	pathExistFn := folder.CheckerFn()
-	appendFn := func(rf *rated.Rated) {
-		res = append(res, util.NormalOrVerbose(
-			fmt.Sprintf("%d\t%s", rf.Points(), rf.Path),
-			fmt.Sprintf("%d\t%d\t%s", rf.TimePoints,
-				rf.SimilarityPoints, rf.Path)))
-	}
-	entries := 0
-	entryLimit := 8
 	for _, rf := range a {
-		if entries == entryLimit {
+		if p.Limit > 0 && len(listed) == p.Offset+p.Limit {
 			break
 		}
-		if !pathExistFn(rf.Path) {
-			continue
+		if pathExistFn(rf.Path) {
+			listed = append(listed, rf)
 		}
-		appendFn(rf)
-		entries++
 	}
-	if len(res) == 1 {
+	res := page(listed, p.Offset, p.Limit)
+	if p.Format != output.Text {
+		var entries []output.Entry
+		for _, rf := range res {
+			entries = append(entries, output.NewEntry(rf, true))
+		}
+		writeOutput(output.Write(os.Stdout, p.Format, entries))
+		return
+	}
+	// only lists shown to the user are numbered for select, not
+	// those read by scripts or editors
+	var paths []string
+	for _, rf := range listed {
+		paths = append(paths, rf.Path)
+	}
+	if err := session.Save(p.DataDir, session.ID(), paths); err != nil {
+		util.Logln("handleList - save session:", err)
+	}
+	if len(res) == 0 {
 		return
 	}
-	fmt.Println(strings.Join(res, "\n"))
+	tpl := newTemplate(p.Template)
+	color := output.UseColor(p.Color, os.Stdout.Fd())
+	link := output.UseHyperlink(p.Hyperlink, os.Stdout.Fd())
+	views := listViews(res, tpl, p, color, link)
+	writeOutput(tpl.Execute(os.Stdout, views, true))
+}
+
+// listViews of the listed results res, numbered from p.Offset on.
+// The paths are shortened relative to each other, to fit the rows
+// of tpl into the terminal width.
+func listViews(res rated.Slice, tpl *output.Template, p pref.Pref,
+	color, link bool) []output.View {
+	var margin int
+	for i, rf := range res {
+		v := output.NewView(p.Offset+i+1, rf, "", true)
+		if m := tpl.Margin(v); m > margin {
+			margin = m
+		}
+	}
+	res.CutLongPaths(margin)
+	var views []output.View
+	for i, rf := range res {
+		display, suffix := rf.Display(), rf.Suffix
+		if color {
+			display = output.Highlight(display, p.List.Start, p.List.Last,
+				rf.Shortened)
+			suffix = output.Highlight(suffix, p.List.Start, p.List.Last,
+				rf.SuffixShortened)
+		}
+		if link {
+			// the link targets the full path, not the shortened one
+			display = output.Hyperlink(rf.Path, display)
+			suffix = output.Hyperlink(rf.Path, suffix)
+		}
+		v := output.NewView(p.Offset+i+1, rf, display, true)
+		v.Suffix = suffix
+		views = append(views, v)
+	}
+	return views
+}
+
+// page of a, up to limit entries from offset on, all if limit is 0.
+// Out of range values give an empty page.
+func page(a rated.Slice, offset, limit int) rated.Slice {
+	if offset < 0 || offset >= len(a) {
+		return nil
+	}
+	a = a[offset:]
+	if limit > 0 && limit < len(a) {
+		a = a[:limit]
+	}
+	return a
+}
+
+// newTemplate from preset name or template text s. If s is empty,
+// the default or verbose preset is used.
+func newTemplate(s string) *output.Template {
+	if s == "" {
+		s = "default"
+		if pref.Verbose {
+			s = "verbose"
+		}
+	}
+	tpl, err := output.NewTemplate(s)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return tpl
+}
+
+// handleSelect prints the n-th path of the last list.
+func handleSelect(dataDir string, n int) {
+	path, err := session.Select(dataDir, session.ID(), n)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "select %d: %v\n", n, err)
+		os.Exit(1)
+	}
+	fmt.Print(path)
+}
+
+func handlePick(r *repo.Repo, q pref.Query) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		log.Fatalf("handlePick - %v\n", err)
+	}
+	defer tty.Close()
+	pathExistFn := folder.CheckerFn()
+	// a failing ranker is reported after the picker restored the terminal
+	var searchErr error
+	src := func(s string) []string {
+		res, err := r.Candidates(pathExistFn, pref.NewQuery(s), pickLimit)
+		if err != nil {
+			searchErr = err
+		}
+		var a []string
+		for _, rf := range res {
+			a = append(a, rf.Path)
+		}
+		return a
+	}
+	path, err := picker.Pick(tty, q.Text(), src)
+	if searchErr != nil {
+		rankerFailed(searchErr)
+	}
+	if err == picker.ErrCanceled {
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("handlePick - %v\n", err)
+	}
+	fmt.Print(path)
+}
+
+// rankerFailed prints err and exits with status exitRankerFailed.
+func rankerFailed(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(exitRankerFailed)
+}
+
+func writeOutput(err error) {
+	if err != nil {
+		log.Fatalf("write output: %v\n", err)
+	}
 }
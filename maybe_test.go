@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thibran/maybe/classify"
+	"github.com/thibran/maybe/output"
+	"github.com/thibran/maybe/pref"
+	"github.com/thibran/maybe/rated"
+	"github.com/thibran/maybe/rated/folder"
+	"github.com/thibran/maybe/repo"
+	"github.com/thibran/maybe/session"
+)
+
+func TestPage(t *testing.T) {
+	var a rated.Slice
+	for _, p := range []string{"/a", "/b", "/c"} {
+		a = append(a, &rated.Rated{Folder: folder.New(p, time.Now())})
+	}
+	tt := []struct {
+		name          string
+		offset, limit int
+		exp           string
+	}{
+		{name: "all", exp: "[/a /b /c]"},
+		{name: "limit", limit: 2, exp: "[/a /b]"},
+		{name: "offset", offset: 1, limit: 1, exp: "[/b]"},
+		{name: "limit past end", offset: 2, limit: 5, exp: "[/c]"},
+		{name: "offset at end", offset: 3, exp: "[]"},
+		{name: "offset past end", offset: 7, limit: 2, exp: "[]"},
+		{name: "negative offset", offset: -1, exp: "[]"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var paths []string
+			for _, rf := range page(a, tc.offset, tc.limit) {
+				paths = append(paths, rf.Path)
+			}
+			if res := fmt.Sprint(paths); res != tc.exp {
+				t.Errorf("exp %s, got %s", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestHandleList_session(t *testing.T) {
+	dir := t.TempDir()
+	foo := filepath.Join(dir, "foo")
+	if err := os.Mkdir(foo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("MAYBE_SESSION", os.Getenv("MAYBE_SESSION"))
+	os.Setenv("MAYBE_SESSION", "test")
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Stdout = devNull
+
+	r := repo.New(filepath.Join(dir, "maybe.data"), 10)
+	r.Add(foo, time.Now())
+	if err := session.Save(dir, "test", []string{"/old"}); err != nil {
+		t.Fatal(err)
+	}
+	p := pref.Pref{DataDir: dir, List: pref.Query{Last: "foo"},
+		Color: output.ColorNever, Hyperlink: output.ColorNever}
+	for _, format := range []string{output.JSON, output.TSV, output.NUL} {
+		p.Format = format
+		handleList(r, p)
+		if a, _ := session.Load(dir, "test"); fmt.Sprint(a) != "[/old]" {
+			t.Fatalf("%s list should keep the session, got %v", format, a)
+		}
+	}
+	p.Format = output.Text
+	handleList(r, p)
+	if a, _ := session.Load(dir, "test"); len(a) != 1 || a[0] != foo {
+		t.Fatalf("exp text list saved, got %v", a)
+	}
+}
+
+func TestListViews_width(t *testing.T) {
+	width := pref.Width
+	defer func() { pref.Width = width }()
+	pref.Width = 40
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", "/nonexistent")
+	now := time.Now()
+	var res rated.Slice
+	for _, p := range []string{"/home/tux/src/github.com/thibran/maybe",
+		"/home/tux/src/github.com/thibran/maybe/classify", "/tmp/x"} {
+		res = append(res, &rated.Rated{Folder: folder.New(p, now),
+			Rating: &classify.Rating{SimilarityPoints: 123}})
+	}
+	res[1].Pin = "cl"
+	for _, name := range []string{"default", "verbose", "full"} {
+		tpl := newTemplate(name)
+		var p pref.Pref
+		p.Offset = 9 // two digit row numbers
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, listViews(res, tpl, p, false, false),
+			false); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(),
+			"\n"), "\n") {
+			if w := output.LineWidth(line); w > pref.Width {
+				t.Errorf("%s: %q is %d columns wide, exp max. %d", name,
+					line, w, pref.Width)
+			}
+		}
+	}
+}
+
+func TestHandleComplete_emptyPartial(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"src/foo", "src/bar/deep", "other/baz"} {
+		if err := os.MkdirAll(filepath.Join(dir, p), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := repo.New(filepath.Join(dir, "maybe.data"), 100)
+	for _, p := range []string{"src/foo", "src/bar/deep", "other/baz"} {
+		r.Add(filepath.Join(dir, p), time.Now())
+	}
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = pw
+	handleComplete(r, pref.Query{Start: "src"})
+	pw.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(pr); err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		values = append(values, strings.SplitN(line, "\t", 2)[0])
+	}
+	sort.Strings(values)
+	if res := fmt.Sprint(values); res != "[bar foo]" {
+		t.Errorf("exp the children of src, got %s", res)
+	}
+}
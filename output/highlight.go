@@ -0,0 +1,178 @@
+package output
+
+import (
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/thibran/maybe/classify"
+	"github.com/thibran/maybe/term"
+)
+
+// Color modes.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+const (
+	colorMatch = "\x1b[1;31m"
+	colorDim   = "\x1b[2m"
+	colorReset = "\x1b[0m"
+	shortened  = "..." // marks segments removed by util.ShortenPathTail
+)
+
+// IsColorMode returns true if s is a known color mode.
+func IsColorMode(s string) bool {
+	switch s {
+	case ColorAuto, ColorAlways, ColorNever:
+		return true
+	}
+	return false
+}
+
+// UseColor returns true if output to fd should be colored in mode.
+// In auto mode $NO_COLOR is honored and fd must be a terminal.
+func UseColor(mode string, fd uintptr) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		return !noColor() && term.IsTerminal(fd)
+	}
+	return false
+}
+
+// noColor returns true if $NO_COLOR is set to a non-empty value,
+// see https://no-color.org.
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// Highlight the matches of query in the base name of path p, or if
+// none, in the parent segments. The first parent segment matching
+// start is highlighted. Segments marked in short, as reported by
+// util.ShortenPathTail, and ... are dimmed.
+func Highlight(p, start, query string, short []bool) string {
+	sep := string(os.PathSeparator)
+	a := strings.Split(p, sep)
+	last := len(a) - 1
+	start = strings.TrimSpace(start)
+	startDone := start == ""
+	base := strings.TrimPrefix(a[last], shortened)
+	baseMatch := classify.Text(base, query) != classify.NoMatch
+	for i, seg := range a {
+		if seg == shortened || (i < len(short) && short[i] && i != last) {
+			a[i] = colorDim + seg + colorReset
+			continue
+		}
+		var prefix string
+		if strings.HasPrefix(seg, shortened) {
+			prefix = colorDim + shortened + colorReset
+			seg = strings.TrimPrefix(seg, shortened)
+		}
+		switch {
+		case i == last:
+			seg = mark(seg, query, baseMatch)
+		case !startDone && matchesStart(seg, start):
+			seg = mark(seg, start, false)
+			startDone = true
+		case !baseMatch:
+			seg = mark(seg, query, false)
+		}
+		a[i] = prefix + seg
+	}
+	return strings.Join(a, sep)
+}
+
+// matchesStart like rated.Slice.FilterInPathOf: an exact
+// or a non-suffix match.
+func matchesStart(seg, start string) bool {
+	seg, start = strings.ToLower(seg), strings.ToLower(start)
+	trimmed := strings.TrimSuffix(seg, start)
+	return seg != "" && (len(trimmed) == 0 || strings.Contains(trimmed, start))
+}
+
+// mark the first case-insensitive occurrence of query in s,
+// or if similar is true, the runes at the same position.
+func mark(s, query string, similar bool) string {
+	rs := []rune(s)
+	m := matchRange(s, query, similar)
+	if m == nil {
+		return s
+	}
+	var b strings.Builder
+	for i, r := range rs {
+		if m[i] && (i == 0 || !m[i-1]) {
+			b.WriteString(colorMatch)
+		}
+		b.WriteRune(r)
+		if m[i] && (i == len(rs)-1 || !m[i+1]) {
+			b.WriteString(colorReset)
+		}
+	}
+	return b.String()
+}
+
+// matchRange returns which runes of s match query,
+// nil if none.
+func matchRange(s, query string, similar bool) []bool {
+	rs, qs := lowerRunes(s), lowerRunes(query)
+	if len(rs) == 0 || len(qs) == 0 {
+		return nil
+	}
+	m := make([]bool, len(rs))
+	if i := runeIndex(rs, qs); i >= 0 {
+		for k := i; k < i+len(qs); k++ {
+			m[k] = true
+		}
+		return m
+	}
+	if !similar {
+		return nil
+	}
+	// similar: ignore leading dots, compare runes by position
+	off, qoff := 0, 0
+	if rs[0] == '.' {
+		off = 1
+	}
+	if qs[0] == '.' {
+		qoff = 1
+	}
+	var found bool
+	for k := off; k < len(rs) && k-off+qoff < len(qs); k++ {
+		if rs[k] == qs[k-off+qoff] {
+			m[k] = true
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return m
+}
+
+func lowerRunes(s string) []rune {
+	rs := []rune(s)
+	for i, r := range rs {
+		rs[i] = unicode.ToLower(r)
+	}
+	return rs
+}
+
+func runeIndex(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for k, r := range sub {
+			if s[i+k] != r {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
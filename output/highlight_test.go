@@ -0,0 +1,89 @@
+package output
+
+import (
+	"os"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	m := func(s string) string { return colorMatch + s + colorReset }
+	dim := func(s string) string { return colorDim + s + colorReset }
+	d := dim(shortened)
+	tt := []struct {
+		name, path, start, query, exp string
+		short                         []bool
+	}{
+		{name: "base name", path: "/home/tux/FooBar", query: "bar",
+			exp: "/home/tux/Foo" + m("Bar")},
+		{name: "similar", path: "/home/bar", query: "bao",
+			exp: "/home/" + m("ba") + "r"},
+		{name: "start", path: "/home/src/foo", start: "src", query: "foo",
+			exp: "/home/" + m("src") + "/" + m("foo")},
+		{name: "parent", path: "/srv/backend/current", query: "backend",
+			exp: "/srv/" + m("backend") + "/current"},
+		{name: "shortened", path: "/home/.../a/foo", query: "foo",
+			exp: "/home/" + d + "/a/" + m("foo")},
+		{name: "abbreviated", path: "/h/t/src/foo", start: "src", query: "foo",
+			short: []bool{false, true, true, false, false},
+			exp:   "/" + dim("h") + "/" + dim("t") + "/" + m("src") + "/" + m("foo")},
+		{name: "abbreviated match", path: "/f/bar", query: "f",
+			short: []bool{false, true, false},
+			exp:   "/" + dim("f") + "/bar"},
+		{name: "cut segment", path: "...ar/foo", query: "foo",
+			exp: d + "ar/" + m("foo")},
+		{name: "no match", path: "/home/zot", query: "foo",
+			exp: "/home/zot"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			res := Highlight(tc.path, tc.start, tc.query, tc.short)
+			if res != tc.exp {
+				t.Errorf("exp %q, got %q", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if !UseColor(ColorAlways, f.Fd()) {
+		t.Error("always should use color")
+	}
+	if UseColor(ColorNever, f.Fd()) {
+		t.Error("never should not use color")
+	}
+	if UseColor(ColorAuto, f.Fd()) {
+		t.Error("auto should not use color for non-terminals")
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	if v, ok := os.LookupEnv("NO_COLOR"); ok {
+		defer os.Setenv("NO_COLOR", v)
+	} else {
+		defer os.Unsetenv("NO_COLOR")
+	}
+	tt := []struct {
+		name, value string
+		set, exp    bool
+	}{
+		{name: "unset"},
+		{name: "empty", set: true},
+		{name: "set", value: "1", set: true, exp: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Unsetenv("NO_COLOR")
+			if tc.set {
+				os.Setenv("NO_COLOR", tc.value)
+			}
+			if res := noColor(); res != tc.exp {
+				t.Errorf("exp %v, got %v", tc.exp, res)
+			}
+		})
+	}
+}
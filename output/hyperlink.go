@@ -0,0 +1,72 @@
+package output
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/thibran/maybe/term"
+)
+
+const (
+	osc8Open  = "\x1b]8;;"
+	osc8Close = "\x1b\\"
+)
+
+// Hyperlink wraps text in an OSC 8 hyperlink to path, which must
+// be absolute. The link target is independent of the shown text.
+func Hyperlink(path, text string) string {
+	return osc8Open + FileURL(path) + osc8Close + text +
+		osc8Open + osc8Close
+}
+
+// FileURL of absolute path, e.g. file://host/home/tux.
+func FileURL(path string) string {
+	host, _ := os.Hostname()
+	u := url.URL{Scheme: "file", Host: host, Path: path}
+	return u.String()
+}
+
+// UseHyperlink returns true if output to fd should contain hyperlinks
+// in mode, one of the color modes. In auto mode fd must be a terminal
+// known to support OSC 8.
+func UseHyperlink(mode string, fd uintptr) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		return term.IsTerminal(fd) && supportsHyperlinks()
+	}
+	return false
+}
+
+// supportsHyperlinks guesses from the environment if the terminal
+// renders OSC 8 hyperlinks. Terminal multiplexers are excluded,
+// they may not pass the sequences through.
+func supportsHyperlinks() bool {
+	t := os.Getenv("TERM")
+	if t == "dumb" || strings.HasPrefix(t, "screen") ||
+		strings.HasPrefix(t, "tmux") {
+		return false
+	}
+	for _, s := range []string{"kitty", "alacritty", "foot", "wezterm",
+		"ghostty"} {
+		if strings.Contains(t, s) {
+			return true
+		}
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper":
+		return true
+	}
+	for _, k := range []string{"WT_SESSION", "KONSOLE_VERSION",
+		"KITTY_WINDOW_ID", "DOMTERM"} {
+		if os.Getenv(k) != "" {
+			return true
+		}
+	}
+	// VTE based terminals, e.g. GNOME Terminal, since 0.50
+	n, err := strconv.Atoi(os.Getenv("VTE_VERSION"))
+	return err == nil && n >= 5000
+}
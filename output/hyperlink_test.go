@@ -0,0 +1,78 @@
+package output
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHyperlink(t *testing.T) {
+	res := Hyperlink("/home/tux/a b", "~/a b")
+	if !strings.HasPrefix(res, "\x1b]8;;file://") {
+		t.Fatalf("missing OSC 8 start: %q", res)
+	}
+	if !strings.HasSuffix(res, "\x1b\\~/a b\x1b]8;;\x1b\\") {
+		t.Errorf("text not wrapped: %q", res)
+	}
+	if !strings.Contains(res, "/home/tux/a%20b\x1b\\") {
+		t.Errorf("path not escaped: %q", res)
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	host, _ := os.Hostname()
+	exp := "file://" + host + "/tmp/%25x"
+	if res := FileURL("/tmp/%x"); res != exp {
+		t.Errorf("exp %q, got %q", exp, res)
+	}
+}
+
+func TestUseHyperlink(t *testing.T) {
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if !UseHyperlink(ColorAlways, f.Fd()) {
+		t.Error("always should use hyperlinks")
+	}
+	if UseHyperlink(ColorNever, f.Fd()) {
+		t.Error("never should not use hyperlinks")
+	}
+	if UseHyperlink(ColorAuto, f.Fd()) {
+		t.Error("auto should not use hyperlinks for non-terminals")
+	}
+}
+
+func TestSupportsHyperlinks(t *testing.T) {
+	for _, k := range []string{"TERM", "TERM_PROGRAM", "WT_SESSION",
+		"KONSOLE_VERSION", "KITTY_WINDOW_ID", "DOMTERM", "VTE_VERSION"} {
+		old, ok := os.LookupEnv(k)
+		if ok {
+			defer os.Setenv(k, old)
+		} else {
+			defer os.Unsetenv(k)
+		}
+		os.Unsetenv(k)
+	}
+	tt := []struct {
+		name, key, value string
+		exp              bool
+	}{
+		{name: "kitty", key: "TERM", value: "xterm-kitty", exp: true},
+		{name: "tmux", key: "TERM", value: "tmux-256color", exp: false},
+		{name: "vte new", key: "VTE_VERSION", value: "6003", exp: true},
+		{name: "vte old", key: "VTE_VERSION", value: "4205", exp: false},
+		{name: "iterm", key: "TERM_PROGRAM", value: "iTerm.app", exp: true},
+		{name: "xterm", key: "TERM", value: "xterm-256color", exp: false},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Setenv(tc.key, tc.value)
+			defer os.Unsetenv(tc.key)
+			if res := supportsHyperlinks(); res != tc.exp {
+				t.Errorf("exp %t, got %t", tc.exp, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Package output writes rated folders in machine-readable formats.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/thibran/maybe/rated"
+)
+
+// Output formats.
+const (
+	Text = "text" // human readable, default
+	JSON = "json"
+	TSV  = "tsv"
+	NUL  = "nul"
+)
+
+// Entry is the machine-readable view of a rated folder.
+type Entry struct {
+	Path             string    `json:"path"`
+	Points           uint      `json:"points"`
+	TimePoints       uint      `json:"time_points"`
+	SimilarityPoints uint      `json:"similarity_points"`
+	FrequencyPoints  uint      `json:"frequency_points"`
+	ExtraPoints      uint      `json:"extra_points"`
+	UpdateCount      uint32    `json:"update_count"`
+	LastVisit        time.Time `json:"last_visit"`
+	Exists           bool      `json:"exists"`
+	Pin              string    `json:"pin,omitempty"`
+}
+
+// NewEntry from rated folder rf, with the full, unshortened path.
+func NewEntry(rf *rated.Rated, exists bool) Entry {
+	e := Entry{
+		Path:        rf.Path,
+		UpdateCount: rf.UpdateCount,
+		Exists:      exists,
+		Pin:         rf.Pin,
+	}
+	if len(rf.Times) > 0 {
+		e.LastVisit = rf.Times[0]
+	}
+	if rf.Rating != nil {
+		e.Points = rf.Points()
+		e.TimePoints = rf.TimePoints
+		e.SimilarityPoints = rf.SimilarityPoints
+		e.FrequencyPoints = rf.FrequencyPoints
+		e.ExtraPoints = rf.ExtraPoints
+	}
+	return e
+}
+
+// Write entries a in format to w. JSON is written as array,
+// TSV as one line per entry and NUL as one NUL terminated record
+// per entry. TSV and NUL fields are tab separated:
+//
+//	points time_points similarity_points frequency_points extra_points
+//	update_count last_visit_unix_time exists path
+//
+// The path is the last field, so it may contain tabs.
+func Write(w io.Writer, format string, a []Entry) error {
+	switch format {
+	case JSON:
+		if a == nil {
+			a = []Entry{}
+		}
+		return encodeJSON(w, a)
+	case TSV:
+		return writeRecords(w, a, '\n')
+	case NUL:
+		return writeRecords(w, a, 0)
+	}
+	return fmt.Errorf("output.Write - unsupported format %q", format)
+}
+
+// WriteOne entry e in format to w. JSON is written as object.
+func WriteOne(w io.Writer, format string, e Entry) error {
+	if format == JSON {
+		return encodeJSON(w, e)
+	}
+	return Write(w, format, []Entry{e})
+}
+
+func encodeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeRecords(w io.Writer, a []Entry, term byte) error {
+	for _, e := range a {
+		var lastVisit int64
+		if !e.LastVisit.IsZero() {
+			lastVisit = e.LastVisit.Unix()
+		}
+		_, err := fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%t\t%s%c",
+			e.Points, e.TimePoints, e.SimilarityPoints, e.FrequencyPoints,
+			e.ExtraPoints, e.UpdateCount, lastVisit, e.Exists, e.Path, term)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
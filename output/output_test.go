@@ -0,0 +1,86 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/thibran/maybe/classify"
+	"github.com/thibran/maybe/rated"
+	"github.com/thibran/maybe/rated/folder"
+)
+
+func TestNewEntry(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	rf := &rated.Rated{
+		Folder:      folder.New("/home/tux/a-very/long/path", now),
+		Rating:      &classify.Rating{TimePoints: 39, SimilarityPoints: 50},
+		DisplayPath: "/home/.../path",
+	}
+	e := NewEntry(rf, true)
+	if e.Path != rf.Path {
+		t.Fatalf("exp unshortened path %q, got %q", rf.Path, e.Path)
+	}
+	if e.Points != 89 || !e.LastVisit.Equal(now) || !e.Exists {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	a := []Entry{
+		{Path: "/home/foo", Points: 50, SimilarityPoints: 50,
+			UpdateCount: 1, LastVisit: time.Unix(1500000000, 0), Exists: true},
+		{Path: "/tmp/with\ttab", UpdateCount: 2},
+	}
+	tt := []struct {
+		name, format, exp string
+	}{
+		{name: "tsv", format: TSV,
+			exp: "50\t0\t50\t0\t0\t1\t1500000000\ttrue\t/home/foo\n" +
+				"0\t0\t0\t0\t0\t2\t0\tfalse\t/tmp/with\ttab\n"},
+		{name: "nul", format: NUL,
+			exp: "50\t0\t50\t0\t0\t1\t1500000000\ttrue\t/home/foo\x00" +
+				"0\t0\t0\t0\t0\t2\t0\tfalse\t/tmp/with\ttab\x00"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tc.format, a); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.exp {
+				t.Fatalf("exp %q, got %q", tc.exp, buf.String())
+			}
+		})
+	}
+}
+
+func TestWrite_json(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, JSON, nil); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); s != "[]\n" {
+		t.Fatalf("exp empty array, got %q", s)
+	}
+	buf.Reset()
+	e := Entry{Path: "/home/foo", Exists: true}
+	if err := WriteOne(&buf, JSON, e); err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"path", "points", "time_points",
+		"similarity_points", "frequency_points", "extra_points",
+		"update_count", "last_visit", "exists"} {
+		if _, ok := m[k]; !ok {
+			t.Errorf("key %q missing", k)
+		}
+	}
+	if err := Write(&buf, Text, nil); err == nil {
+		t.Fatal("text should not be supported")
+	}
+}
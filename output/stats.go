@@ -0,0 +1,84 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/thibran/maybe/repo"
+	"github.com/thibran/maybe/util"
+)
+
+const barWidth = 40 // max. width of a histogram bar
+
+// WriteStats s in format to w, text or JSON.
+func WriteStats(w io.Writer, format string, s repo.Stats) error {
+	switch format {
+	case JSON:
+		return encodeJSON(w, s)
+	case Text:
+		return writeStatsText(w, s)
+	}
+	return fmt.Errorf("output.WriteStats - unsupported format %q", format)
+}
+
+func writeStatsText(w io.Writer, s repo.Stats) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "entries     %d / %d\n", s.Entries, s.MaxEntries)
+	fmt.Fprintf(bw, "data file   %s\n", byteSize(s.FileSize))
+	fmt.Fprintf(bw, "missing     %d\n", s.Missing)
+	fmt.Fprintf(bw, "init only   %d\n", s.InitOnly)
+	fmt.Fprintf(bw, "evictions   %d\n", s.Evictions)
+	if !s.LastPrune.IsZero() {
+		fmt.Fprintf(bw, "last prune  %s\n", s.LastPrune.Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintln(bw, "\nMost visited")
+	for _, v := range s.MostVisited {
+		fmt.Fprintf(bw, "%6d  %s\n", v.Visits, util.Tilde(v.Path))
+	}
+	fmt.Fprintln(bw, "\nRecently visited")
+	for _, v := range s.Recent {
+		fmt.Fprintf(bw, "  %s  %s\n", v.LastVisit.Format("2006-01-02 15:04"),
+			util.Tilde(v.Path))
+	}
+	fmt.Fprintln(bw, "\nVisits per day")
+	writeHistogram(bw, s.PerDay, "Mon 01-02")
+	fmt.Fprintln(bw, "\nVisits per week")
+	writeHistogram(bw, s.PerWeek, "2006-01-02")
+	return bw.Flush()
+}
+
+// writeHistogram of a as bars scaled to barWidth, each labeled
+// with the start of its bucket.
+func writeHistogram(w io.Writer, a []repo.Bucket, layout string) {
+	max := 0
+	for _, b := range a {
+		if b.Visits > max {
+			max = b.Visits
+		}
+	}
+	for _, b := range a {
+		n := b.Visits
+		if max > barWidth {
+			n = (n*barWidth + max - 1) / max
+		}
+		line := fmt.Sprintf("  %s %5d %s", b.Start.Format(layout), b.Visits,
+			strings.Repeat("#", n))
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+}
+
+// byteSize of n, e.g. 1.5 KiB.
+func byteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
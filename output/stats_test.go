@@ -0,0 +1,63 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thibran/maybe/repo"
+)
+
+func TestWriteStats(t *testing.T) {
+	day := time.Date(2020, time.May, 20, 0, 0, 0, 0, time.UTC)
+	s := repo.Stats{Entries: 3, MaxEntries: 10, FileSize: 1536, Missing: 1,
+		MostVisited: []repo.Visited{{Path: "/foo", Visits: 7, LastVisit: day}},
+		PerDay: []repo.Bucket{{Start: day, Visits: 2},
+			{Start: day.AddDate(0, 0, 1), Visits: 80}},
+	}
+	var buf bytes.Buffer
+	if err := WriteStats(&buf, Text, s); err != nil {
+		t.Fatal(err)
+	}
+	for _, exp := range []string{"entries     3 / 10\n",
+		"data file   1.5 KiB\n", "     7  /foo\n",
+		"  Wed 05-20     2 #\n",
+		"  Thu 05-21    80 " + strings.Repeat("#", barWidth) + "\n"} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("exp %q in %q", exp, buf.String())
+		}
+	}
+	buf.Reset()
+	if err := WriteStats(&buf, JSON, s); err != nil {
+		t.Fatal(err)
+	}
+	var res repo.Stats
+	if err := json.Unmarshal(buf.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Entries != 3 || len(res.PerDay) != 2 || res.PerDay[1].Visits != 80 {
+		t.Errorf("unexpected stats %+v", res)
+	}
+	if err := WriteStats(&buf, TSV, s); err == nil {
+		t.Error("exp unsupported format error")
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	tt := []struct {
+		n   int64
+		exp string
+	}{
+		{n: 0, exp: "0 B"},
+		{n: 1023, exp: "1023 B"},
+		{n: 1024, exp: "1.0 KiB"},
+		{n: 5 << 20, exp: "5.0 MiB"},
+	}
+	for _, tc := range tt {
+		if res := byteSize(tc.n); res != tc.exp {
+			t.Errorf("exp %q, got %q", tc.exp, res)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/thibran/maybe/rated"
+	"github.com/thibran/maybe/util"
+)
+
+// View of a rated folder, the data of a template row.
+type View struct {
+	Index            int    // row number, starting with 1
+	Path             string // full path
+	DisplayPath      string // shortened and highlighted path
+	Suffix           string // shortest unique trailing path, highlighted
+	Points           uint   // sum of all points
+	TimePoints       uint
+	SimilarityPoints uint
+	FrequencyPoints  uint
+	ExtraPoints      uint
+	UpdateCount      uint32    // number of visits
+	LastVisit        time.Time // zero if unknown
+	Exists           bool
+	Pin              string // alias of a pinned folder
+}
+
+// NewView of rated folder rf, shown as display. The Suffix is the
+// base name, if CutLongPaths didn't set one.
+func NewView(index int, rf *rated.Rated, display string, exists bool) View {
+	e := NewEntry(rf, exists)
+	suffix := rf.Suffix
+	if suffix == "" {
+		suffix = filepath.Base(rf.Path)
+	}
+	return View{
+		Index:            index,
+		Path:             e.Path,
+		DisplayPath:      display,
+		Suffix:           suffix,
+		Points:           e.Points,
+		TimePoints:       e.TimePoints,
+		SimilarityPoints: e.SimilarityPoints,
+		FrequencyPoints:  e.FrequencyPoints,
+		ExtraPoints:      e.ExtraPoints,
+		UpdateCount:      e.UpdateCount,
+		LastVisit:        e.LastVisit,
+		Exists:           e.Exists,
+		Pin:              e.Pin,
+	}
+}
+
+// Preset template with optional header.
+type Preset struct {
+	Header, Row string
+}
+
+// Presets of named templates.
+var Presets = map[string]Preset{
+	"default": {Header: "#\tRating\tFolder",
+		Row: "{{.Index}}\t{{.Points}}\t{{.Suffix}}" + pinMark},
+	"full": {Header: "#\tRating\tFolder",
+		Row: "{{.Index}}\t{{.Points}}\t{{.DisplayPath}}" + pinMark},
+	"verbose": {Header: "#\tTime\tFreq\tText\tFolder",
+		Row: "{{.Index}}\t{{.TimePoints}}\t{{.FrequencyPoints}}\t" +
+			"{{.SimilarityPoints}}\t{{.DisplayPath}}" + pinMark},
+	"visits": {Header: "#\tVisits\tLast\tFolder",
+		Row: "{{.Index}}\t{{.UpdateCount}}\t{{ago .LastVisit}}\t{{tilde .Path}}" +
+			pinMark},
+	"path": {Row: "{{.Path}}"},
+}
+
+// pinMark follows the folder of pinned results in the presets.
+const pinMark = "{{with .Pin}} [{{.}}]{{end}}"
+
+// PresetNames in alphabetical order.
+func PresetNames() []string {
+	var a []string
+	for k := range Presets {
+		a = append(a, k)
+	}
+	sort.Strings(a)
+	return a
+}
+
+var funcs = template.FuncMap{
+	"ago":   Ago,
+	"tilde": util.Tilde,
+}
+
+// Template renders views line by line.
+type Template struct {
+	header string
+	row    *template.Template
+}
+
+// NewTemplate from a preset name or a text/template
+// executed for every View.
+func NewTemplate(s string) (*Template, error) {
+	p, ok := Presets[s]
+	if !ok {
+		p = Preset{Row: s}
+	}
+	row, err := template.New("row").Funcs(funcs).Parse(p.Row)
+	if err != nil {
+		return nil, fmt.Errorf("template: %v", err)
+	}
+	return &Template{header: p.Header, row: row}, nil
+}
+
+// Execute the template for all views a, every row ends with a newline.
+// The header is printed first, if it exists and header is true.
+func (t *Template) Execute(w io.Writer, a []View, header bool) error {
+	if header && t.header != "" {
+		if _, err := fmt.Fprintln(w, t.header); err != nil {
+			return err
+		}
+	}
+	for _, v := range a {
+		if err := t.ExecuteOne(w, v); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExecuteOne renders view v without header and trailing newline.
+func (t *Template) ExecuteOne(w io.Writer, v View) error {
+	return t.row.Execute(w, v)
+}
+
+// Margin returns the columns of the row of v besides its DisplayPath,
+// or Suffix, with tabs expanded. It is 0 if the row shows neither.
+func (t *Template) Margin(v View) int {
+	const path = "\x00"
+	v.DisplayPath, v.Suffix = path, path
+	var b strings.Builder
+	if err := t.row.Execute(&b, v); err != nil {
+		return 0
+	}
+	s := b.String()
+	i := strings.Index(s, path)
+	if i < 0 {
+		return 0
+	}
+	// the columns after the path hold no tabs, e.g. the pin alias
+	return LineWidth(s[:i]) + util.StringWidth(s[i+len(path):])
+}
+
+// LineWidth returns the terminal columns of line s, with tabs
+// expanded to the next multiple of 8.
+func LineWidth(s string) int {
+	var n int
+	for _, r := range s {
+		if r == '\t' {
+			n += 8 - n%8
+			continue
+		}
+		n += util.RuneWidth(r)
+	}
+	return n
+}
+
+// Ago returns the rounded time since t, e.g. "3h ago".
+func Ago(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	d := time.Since(t)
+	day := time.Hour * 24
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", d/time.Minute)
+	case d < day:
+		return fmt.Sprintf("%dh ago", d/time.Hour)
+	case d < day*7*2:
+		return fmt.Sprintf("%dd ago", d/day)
+	case d < day*30*2:
+		return fmt.Sprintf("%dw ago", d/(day*7))
+	case d < day*365:
+		return fmt.Sprintf("%dmo ago", d/(day*30))
+	}
+	return fmt.Sprintf("%dy ago", d/(day*365))
+}
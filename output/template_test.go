@@ -0,0 +1,133 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTemplate(t *testing.T) {
+	a := []View{
+		{Index: 1, Path: "/home/foo", DisplayPath: "/home/foo", Suffix: "foo",
+			Points: 50},
+		{Index: 2, Path: "/tmp/bar", DisplayPath: "/tmp/bar", Suffix: "bar",
+			Points: 20, Pin: "b"},
+	}
+	tt := []struct {
+		name, tpl, exp string
+		header         bool
+		err            bool
+	}{
+		{name: "preset", tpl: "default", header: true,
+			exp: "#\tRating\tFolder\n1\t50\tfoo\n2\t20\tbar [b]\n"},
+		{name: "full preset", tpl: "full", header: true,
+			exp: "#\tRating\tFolder\n1\t50\t/home/foo\n2\t20\t/tmp/bar [b]\n"},
+		{name: "preset without header", tpl: "path",
+			exp: "/home/foo\n/tmp/bar\n"},
+		{name: "custom", tpl: "{{.Points}} {{.Path}}", header: true,
+			exp: "50 /home/foo\n20 /tmp/bar\n"},
+		{name: "invalid", tpl: "{{.Points", err: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tpl, err := NewTemplate(tc.tpl)
+			if tc.err {
+				if err == nil {
+					t.Fatal("should fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := tpl.Execute(&buf, a, tc.header); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.exp {
+				t.Fatalf("exp %q, got %q", tc.exp, buf.String())
+			}
+		})
+	}
+}
+
+func TestPresets(t *testing.T) {
+	v := View{Index: 1, Path: "/home/foo", LastVisit: time.Now()}
+	for _, name := range PresetNames() {
+		tpl, err := NewTemplate(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := tpl.ExecuteOne(&bytes.Buffer{}, v); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestMargin(t *testing.T) {
+	v := View{Index: 12, Points: 123456789, Path: "/home/foo"}
+	tt := []struct {
+		name, tpl, pin string
+		exp            int
+	}{
+		{name: "default", tpl: "default", exp: 24},
+		{name: "pinned", tpl: "default", pin: "w", exp: 28},
+		{name: "verbose", tpl: "verbose", exp: 32},
+		{name: "full", tpl: "full", exp: 24},
+		{name: "no path", tpl: "path", exp: 0},
+		{name: "custom", tpl: "{{.Index}}) {{.DisplayPath}} ·", exp: 6},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tpl, err := NewTemplate(tc.tpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v.Pin = tc.pin
+			if res := tpl.Margin(v); res != tc.exp {
+				t.Errorf("exp %d, got %d", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestLineWidth(t *testing.T) {
+	tt := []struct {
+		s   string
+		exp int
+	}{
+		{s: "", exp: 0},
+		{s: "1\t50\t/foo", exp: 20},
+		{s: "12345678\tx", exp: 17},
+		{s: "\t日本", exp: 12},
+	}
+	for _, tc := range tt {
+		if res := LineWidth(tc.s); res != tc.exp {
+			t.Errorf("%q - exp %d, got %d", tc.s, tc.exp, res)
+		}
+	}
+}
+
+func TestAgo(t *testing.T) {
+	now := time.Now()
+	tt := []struct {
+		name, exp string
+		t         time.Time
+	}{
+		{name: "never", exp: "never"},
+		{name: "now", t: now, exp: "now"},
+		{name: "minutes", t: now.Add(-time.Minute * 5), exp: "5m ago"},
+		{name: "hours", t: now.Add(-time.Hour * 3), exp: "3h ago"},
+		{name: "days", t: now.Add(-time.Hour * 24 * 3), exp: "3d ago"},
+		{name: "weeks", t: now.Add(-time.Hour * 24 * 21), exp: "3w ago"},
+		{name: "months", t: now.Add(-time.Hour * 24 * 100), exp: "3mo ago"},
+		{name: "years", t: now.Add(-time.Hour * 24 * 800), exp: "2y ago"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if s := Ago(tc.t); s != tc.exp {
+				t.Errorf("exp %q, got %q", tc.exp, s)
+			}
+		})
+	}
+}
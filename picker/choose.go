@@ -0,0 +1,87 @@
+package picker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Choose lets the user select any number of paths. The numbered
+// paths and the prompt verb are printed to w, the answer is one line
+// read from r: numbers separated by spaces or commas, n-m for a range
+// or a for all paths. An empty answer returns ErrCanceled.
+func Choose(r io.Reader, w io.Writer, verb string,
+	paths []string) ([]string, error) {
+	for i, p := range paths {
+		fmt.Fprintf(w, "%d\t%s\n", i+1, p)
+	}
+	fmt.Fprintf(w, "%s (e.g. 1 3-5, a for all): ", verb)
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	idx, err := parseChoice(line, len(paths))
+	if err != nil {
+		return nil, err
+	}
+	if len(idx) == 0 {
+		return nil, ErrCanceled
+	}
+	var res []string
+	for _, i := range idx {
+		res = append(res, paths[i])
+	}
+	return res, nil
+}
+
+// parseChoice returns the unique, zero based indexes of the numbers
+// in s, in the order given. Numbers range from 1 to n.
+func parseChoice(s string, n int) ([]int, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	var res []int
+	seen := make(map[int]bool)
+	add := func(i int) {
+		if !seen[i] {
+			seen[i] = true
+			res = append(res, i)
+		}
+	}
+	num := func(s string) (int, error) {
+		i, err := strconv.Atoi(s)
+		if err != nil || i < 1 || i > n {
+			return 0, fmt.Errorf("invalid choice %q, expected 1 to %d", s, n)
+		}
+		return i, nil
+	}
+	for _, f := range fields {
+		if f == "a" || f == "all" {
+			for i := 0; i < n; i++ {
+				add(i)
+			}
+			continue
+		}
+		from, to := f, f
+		if i := strings.Index(f, "-"); i > 0 {
+			from, to = f[:i], f[i+1:]
+		}
+		a, err := num(from)
+		if err != nil {
+			return nil, err
+		}
+		b, err := num(to)
+		if err != nil {
+			return nil, err
+		}
+		if a > b {
+			return nil, fmt.Errorf("invalid range %q", f)
+		}
+		for i := a; i <= b; i++ {
+			add(i - 1)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,56 @@
+package picker
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseChoice(t *testing.T) {
+	tt := []struct {
+		name, in string
+		exp      []int
+		err      bool
+	}{
+		{name: "empty", in: "\n"},
+		{name: "numbers", in: "3 1,2\n", exp: []int{2, 0, 1}},
+		{name: "range", in: "2-4", exp: []int{1, 2, 3}},
+		{name: "duplicates", in: "2 1-2", exp: []int{1, 0}},
+		{name: "all", in: "a", exp: []int{0, 1, 2, 3}},
+		{name: "out of range", in: "5", err: true},
+		{name: "zero", in: "0", err: true},
+		{name: "reverse range", in: "3-1", err: true},
+		{name: "text", in: "foo", err: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := parseChoice(tc.in, 4)
+			if (err != nil) != tc.err {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if fmt.Sprint(res) != fmt.Sprint(tc.exp) {
+				t.Errorf("exp %v, got %v", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestChoose(t *testing.T) {
+	paths := []string{"/home/foo", "/tmp/foo"}
+	var w bytes.Buffer
+	res, err := Choose(strings.NewReader("2\n"), &w, "select", paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0] != "/tmp/foo" {
+		t.Errorf("exp [/tmp/foo], got %v", res)
+	}
+	if !strings.HasPrefix(w.String(), "1\t/home/foo\n2\t/tmp/foo\n") {
+		t.Errorf("unexpected output %q", w.String())
+	}
+	if _, err := Choose(strings.NewReader(""), &w, "select",
+		paths); err != ErrCanceled {
+		t.Errorf("exp ErrCanceled, got %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+// Package picker implements a full-screen terminal selector and a
+// numbered multiple choice prompt.
+package picker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/thibran/maybe/term"
+	"github.com/thibran/maybe/util"
+)
+
+// ErrCanceled is returned when the user aborts the selection.
+var ErrCanceled = errors.New("canceled")
+
+// Source returns the candidate paths for query.
+type Source func(query string) []string
+
+type key int
+
+const (
+	keyRune key = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyCancel
+	keyBackspace
+	keyClear
+	keyNone
+)
+
+const (
+	previewMax = 10 // max. preview height
+	minRows    = 6
+	minCols    = 20
+)
+
+// Pick lets the user select one of the paths returned by src.
+// The terminal tty is used for input and drawing, query
+// is the initial search query.
+func Pick(tty *os.File, query string, src Source) (string, error) {
+	restore, err := term.MakeRaw(tty.Fd())
+	if err != nil {
+		return "", fmt.Errorf("picker - %v", err)
+	}
+	defer restore()
+	// alternate screen, restored on exit
+	fmt.Fprint(tty, "\x1b[?1049h")
+	defer fmt.Fprint(tty, "\x1b[?1049l")
+
+	s := newState(query, src)
+	in := bufio.NewReader(tty)
+	for {
+		cols, rows, err := term.Size(tty.Fd())
+		if err != nil || cols < minCols || rows < minRows {
+			cols, rows = 80, 24
+		}
+		fmt.Fprint(tty, s.render(cols, rows))
+		k, r, err := readKey(in)
+		if err != nil {
+			return "", err
+		}
+		if done := s.update(k, r); done {
+			break
+		}
+	}
+	if s.canceled || len(s.items) == 0 {
+		return "", ErrCanceled
+	}
+	return s.items[s.sel], nil
+}
+
+// state of the picker.
+type state struct {
+	src      Source
+	query    []rune
+	items    []string
+	sel, top int
+	canceled bool
+}
+
+func newState(query string, src Source) *state {
+	s := &state{src: src, query: []rune(query)}
+	s.refresh()
+	return s
+}
+
+// refresh items for the current query.
+func (s *state) refresh() {
+	s.items = s.src(string(s.query))
+	s.sel, s.top = 0, 0
+}
+
+// update state for key k, returns true when the picker is done.
+func (s *state) update(k key, r rune) bool {
+	switch k {
+	case keyEnter:
+		return true
+	case keyCancel:
+		s.canceled = true
+		return true
+	case keyUp:
+		if s.sel > 0 {
+			s.sel--
+		}
+	case keyDown:
+		if s.sel < len(s.items)-1 {
+			s.sel++
+		}
+	case keyBackspace:
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			s.refresh()
+		}
+	case keyClear:
+		s.query = nil
+		s.refresh()
+	case keyRune:
+		s.query = append(s.query, r)
+		s.refresh()
+	}
+	return false
+}
+
+// readKey from r, arrow keys are read as escape sequences.
+func readKey(r *bufio.Reader) (key, rune, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return keyNone, 0, err
+	}
+	switch c {
+	case '\r', '\n':
+		return keyEnter, 0, nil
+	case 0x03, 0x07: // ctrl-c, ctrl-g
+		return keyCancel, 0, nil
+	case 0x10: // ctrl-p
+		return keyUp, 0, nil
+	case 0x0e: // ctrl-n
+		return keyDown, 0, nil
+	case 0x7f, 0x08:
+		return keyBackspace, 0, nil
+	case 0x15: // ctrl-u
+		return keyClear, 0, nil
+	case 0x1b:
+		// a lone escape cancels, sequences arrive in one read
+		if r.Buffered() == 0 {
+			return keyCancel, 0, nil
+		}
+		return readEscape(r)
+	}
+	if unicode.IsPrint(c) {
+		return keyRune, c, nil
+	}
+	return keyNone, 0, nil
+}
+
+// readEscape sequence, e.g. \x1b[A or \x1bOA for arrow up.
+func readEscape(r *bufio.Reader) (key, rune, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return keyNone, 0, err
+	}
+	if c != '[' && c != 'O' {
+		return keyNone, 0, nil
+	}
+	for {
+		c, _, err = r.ReadRune()
+		if err != nil {
+			return keyNone, 0, err
+		}
+		// parameter bytes
+		if c >= '0' && c <= '9' || c == ';' {
+			continue
+		}
+		break
+	}
+	switch c {
+	case 'A':
+		return keyUp, 0, nil
+	case 'B':
+		return keyDown, 0, nil
+	}
+	return keyNone, 0, nil
+}
+
+// render the screen for a terminal of size cols x rows.
+func (s *state) render(cols, rows int) string {
+	previewH := rows / 3
+	if previewH > previewMax {
+		previewH = previewMax
+	}
+	listH := rows - 2 - previewH // prompt and separator line
+	// keep selection visible
+	if s.sel < s.top {
+		s.top = s.sel
+	}
+	if s.sel >= s.top+listH {
+		s.top = s.sel - listH + 1
+	}
+	var lines []string
+	prompt := "> " + string(s.query)
+	lines = append(lines,
+		fit(fmt.Sprintf("%s  (%d)", prompt, len(s.items)), cols))
+	for i := s.top; i < s.top+listH; i++ {
+		if i >= len(s.items) {
+			lines = append(lines, "")
+			continue
+		}
+		line := fit("  "+s.items[i], cols)
+		if i == s.sel {
+			line = "\x1b[7m" + fit("> "+s.items[i], cols) + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "\x1b[2m"+strings.Repeat("-", cols)+"\x1b[0m")
+	var preview []string
+	if len(s.items) > 0 {
+		preview = previewOf(s.items[s.sel], previewH)
+	}
+	for i := 0; i < previewH; i++ {
+		if i < len(preview) {
+			lines = append(lines, fit(preview[i], cols))
+			continue
+		}
+		lines = append(lines, "")
+	}
+	// clear screen, draw and move the cursor behind the prompt,
+	// wide runes take two columns
+	col := util.StringWidth(prompt) + 1
+	if col > cols {
+		col = cols
+	}
+	return "\x1b[H\x1b[2J" + strings.Join(lines, "\r\n") +
+		fmt.Sprintf("\x1b[1;%dH", col)
+}
+
+// previewOf the directory content of path, at most n entries.
+func previewOf(path string, n int) []string {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var a []string
+	for _, e := range entries {
+		if len(a) == n {
+			break
+		}
+		name := e.Name()
+		if e.IsDir() {
+			name += string(filepath.Separator)
+		}
+		a = append(a, "  "+name)
+	}
+	if len(a) == 0 {
+		a = append(a, "  (empty)")
+	}
+	return a
+}
+
+// fit s into n terminal columns, wide runes count twice.
+func fit(s string, n int) string {
+	var w int
+	for i, r := range s {
+		if w += util.RuneWidth(r); w > n {
+			return s[:i]
+		}
+	}
+	return s
+}
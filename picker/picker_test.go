@@ -0,0 +1,137 @@
+package picker
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadKey(t *testing.T) {
+	tt := []struct {
+		name, in string
+		exp      key
+		r        rune
+	}{
+		{name: "rune", in: "a", exp: keyRune, r: 'a'},
+		{name: "enter", in: "\r", exp: keyEnter},
+		{name: "ctrl-p", in: "\x10", exp: keyUp},
+		{name: "ctrl-n", in: "\x0e", exp: keyDown},
+		{name: "arrow up", in: "\x1b[A", exp: keyUp},
+		{name: "arrow down", in: "\x1bOB", exp: keyDown},
+		{name: "arrow with modifier", in: "\x1b[1;5B", exp: keyDown},
+		{name: "escape", in: "\x1b", exp: keyCancel},
+		{name: "ctrl-c", in: "\x03", exp: keyCancel},
+		{name: "backspace", in: "\x7f", exp: keyBackspace},
+		{name: "unknown", in: "\x01", exp: keyNone},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			k, r, err := readKey(bufio.NewReader(strings.NewReader(tc.in)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k != tc.exp || r != tc.r {
+				t.Fatalf("exp %v %q, got %v %q", tc.exp, tc.r, k, r)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	paths := []string{"/home/foo", "/home/foobar", "/tmp/bar"}
+	var queries []string
+	src := func(query string) []string {
+		queries = append(queries, query)
+		var a []string
+		for _, p := range paths {
+			if strings.Contains(p, query) {
+				a = append(a, p)
+			}
+		}
+		return a
+	}
+	s := newState("fo", src)
+	if len(s.items) != 2 {
+		t.Fatalf("exp 2 items, got %v", s.items)
+	}
+	s.update(keyDown, 0)
+	s.update(keyDown, 0)
+	if s.sel != 1 {
+		t.Fatalf("selection should stop at last item, got %d", s.sel)
+	}
+	s.update(keyRune, 'o')
+	s.update(keyRune, 'b')
+	if s.sel != 0 || len(s.items) != 1 || s.items[0] != "/home/foobar" {
+		t.Fatalf("unexpected state after typing: %+v", s)
+	}
+	s.update(keyClear, 0)
+	if len(s.items) != 3 {
+		t.Fatalf("exp all items, got %v", s.items)
+	}
+	if done := s.update(keyEnter, 0); !done || s.canceled {
+		t.Fatal("enter should select")
+	}
+	if exp := []string{"fo", "foo", "foob", ""}; strings.Join(queries, ",") !=
+		strings.Join(exp, ",") {
+		t.Fatalf("exp queries %q, got %q", exp, queries)
+	}
+}
+
+func TestRender(t *testing.T) {
+	s := newState("", func(string) []string {
+		return []string{"/a", "/b", "/c", "/d", "/e"}
+	})
+	s.sel = 4
+	out := s.render(20, 6) // list height 2
+	if s.top != 3 {
+		t.Fatalf("selection should be scrolled into view, top is %d", s.top)
+	}
+	if !strings.Contains(out, "> /e") {
+		t.Fatalf("selected row missing in %q", out)
+	}
+}
+
+func TestRender_cursor(t *testing.T) {
+	tt := []struct {
+		name, query string
+		cols, exp   int
+	}{
+		{name: "empty", cols: 20, exp: 3},
+		{name: "ascii", query: "foo", cols: 20, exp: 6},
+		{name: "wide", query: "日本", cols: 20, exp: 7},
+		{name: "emoji", query: "a🙂", cols: 20, exp: 6},
+		{name: "too long", query: "日本語日本語日本語", cols: 10, exp: 10},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newState(tc.query, func(string) []string { return nil })
+			out := s.render(tc.cols, 6)
+			exp := fmt.Sprintf("\x1b[1;%dH", tc.exp)
+			if !strings.HasSuffix(out, exp) {
+				t.Errorf("exp %q, got %q", exp, out[len(out)-len(exp):])
+			}
+		})
+	}
+}
+
+func TestFit(t *testing.T) {
+	tt := []struct {
+		name, s string
+		n       int
+		exp     string
+	}{
+		{name: "fits", s: "/home/tux", n: 9, exp: "/home/tux"},
+		{name: "ascii", s: "/home/tux", n: 5, exp: "/home"},
+		{name: "wide", s: "/home/日本語", n: 9, exp: "/home/日"},
+		{name: "wide fits", s: "/日本語", n: 7, exp: "/日本語"},
+		{name: "combining", s: "/cafe\u0301s", n: 5, exp: "/cafe\u0301"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if res := fit(tc.s, tc.n); res != tc.exp {
+				t.Errorf("exp %q, got %q", tc.exp, res)
+			}
+		})
+	}
+}
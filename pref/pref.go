@@ -11,51 +11,679 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Verbose output
 var (
 	Verbose = false
+	// Width of the terminal, detected if 0
+	Width = 0
 )
 
 const (
-	maxEntries    = 10000
-	minMaxEntries = 200 // minimal value for the maxEntries variable
+	// DefaultLimit of results printed by list
+	DefaultLimit = 8
+	// DefaultPruneAfter is the time a folder may be missing, before
+	// prune removes it
+	DefaultPruneAfter = 30 * 24 * time.Hour
+	maxEntries        = 10000
+	minMaxEntries     = 200 // minimal value for the maxEntries variable
+)
+
+// Commands of maybe.
+const (
+	CmdAdd        = "add"
+	CmdRemove     = "remove"
+	CmdRemoveTree = "remove-tree"
+	CmdForget     = "forget"
+	CmdPrune      = "prune"
+	CmdStats      = "stats"
+	CmdPin        = "pin"
+	CmdUnpin      = "unpin"
+	CmdPins       = "pins"
+	CmdSearch     = "search"
+	CmdList       = "list"
+	CmdPick       = "pick"
+	CmdExec       = "exec"
+	CmdSelect     = "select"
+	CmdInit       = "init"
+	CmdComplete   = "complete"
+	CmdEvaluate   = "evaluate"
+	CmdVersion    = "version"
+	CmdHelp       = "help"
+)
+
+// Output formats of -format, written by package output.
+var (
+	listFormats  = []string{"text", "json", "tsv", "nul"}
+	statsFormats = []string{"text", "json"}
 )
 
 // Pref object.
 type Pref struct {
+	Command               string   // empty if none was given
+	Args                  []string // remaining arguments of the command
 	DataDir, HomeDir, Add string
-	List, Search          Query
-	Version, Init         bool
-	MaxEntries            int
+	Remove                string // path of remove and remove-tree
+	Ranker, Evaluate      string
+	Format, Color         string
+	Hyperlink             string
+	Template              string
+	List, Search, Pick    Query
+	Exec                  Query // Args holds the command line
+	Forget                Query
+	// PruneAfter is the time a folder may be missing before prune
+	// removes it, AutoPruneAfter the same for add, which prunes
+	// once a day if it is positive
+	PruneAfter, AutoPruneAfter time.Duration
+	DryRun                     bool
+	// Pin is the alias of pin, the alias or path of unpin
+	Pin, PinPath         string
+	Record               bool // exec records the visit
+	Complete             Query
+	MaxEntries           int
+	MinScore, Confidence uint
+	Limit, Offset        int // Limit 0 is unlimited
+	Select               int
+	// InitShell is set by: maybe init <shell>
+	InitShell              string
+	ShellCmd, ShellListCmd string
+	ShellHook              bool
+	ShellCompletion        bool
+}
+
+// Command description, as printed by help.
+type Command struct {
+	Name, Args, Usage string
+	Doc               string // details, printed by help <command>
+	// cmdline: the arguments after -- are a command line, set as Args
+	cmdline bool
+	// formats of -format, none if the command has no such flag
+	formats []string
+	flags   func(fs *flag.FlagSet, p *Pref)
+	// parse the positional arguments
+	parse func(p *Pref, args []string) error
+}
+
+// Commands in the order printed by help.
+func Commands() []Command {
+	return []Command{
+		{Name: CmdSearch, Args: "[start] <keyword>",
+			Usage:   "print the best rated folder for keyword",
+			formats: listFormats,
+			flags: func(fs *flag.FlagSet, p *Pref) {
+				commonFlags(fs, p)
+				formatFlags(fs, p)
+				scoreFlags(fs, p)
+			},
+			parse: func(p *Pref, args []string) (err error) {
+				p.Search, err = queryArgs(args)
+				return
+			}},
+		{Name: CmdList, Args: "[start] <keyword>",
+			Usage:   "list the results for keyword",
+			formats: listFormats,
+			flags: func(fs *flag.FlagSet, p *Pref) {
+				commonFlags(fs, p)
+				outputFlags(fs, p)
+				limitFlags(fs, p)
+			},
+			parse: func(p *Pref, args []string) (err error) {
+				p.List, err = queryArgs(args)
+				return
+			}},
+		{Name: CmdPick, Args: "[start] <keyword>",
+			Usage: "interactively pick a result for keyword",
+			flags: commonFlags,
+			parse: func(p *Pref, args []string) (err error) {
+				p.Pick, err = queryArgs(args)
+				return
+			}},
+		{Name: CmdExec, Args: "[start] <keyword> -- <command> [args...]",
+			Usage: "run command in the best rated folder for keyword",
+			Doc: "Exits with the status of command, 128 plus the signal number if\n" +
+				"command was killed, 127 if it can't be started, or 125 if keyword\n" +
+				"has no result, or it is too weak or ambiguous.",
+			cmdline: true,
+			flags: func(fs *flag.FlagSet, p *Pref) {
+				commonFlags(fs, p)
+				scoreFlags(fs, p)
+				fs.BoolVar(&p.Record, "record", p.Record, "add the folder to index")
+			},
+			parse: func(p *Pref, args []string) (err error) {
+				if len(p.Args) == 0 {
+					return fmt.Errorf("expected command after --")
+				}
+				p.Exec, err = queryArgs(args)
+				return
+			}},
+		{Name: CmdSelect, Args: "<n>",
+			Usage: "print the n-th result of the last list",
+			flags: commonFlags,
+			parse: func(p *Pref, args []string) error {
+				if len(args) != 1 {
+					return fmt.Errorf("expected one number")
+				}
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n == 0 {
+					return fmt.Errorf("invalid number %q", args[0])
+				}
+				p.Select = n
+				return nil
+			}},
+		{Name: CmdAdd, Args: "<path>",
+			Usage: "add path to index",
+			flags: func(fs *flag.FlagSet, p *Pref) {
+				commonFlags(fs, p)
+				fs.Var((*duration)(&p.AutoPruneAfter), "prune-after",
+					"prune folders missing longer, once a day, e.g. 30d")
+			},
+			parse: func(p *Pref, args []string) error {
+				if len(args) != 1 {
+					return fmt.Errorf("expected one path")
+				}
+				p.Add = args[0]
+				return nil
+			}},
+		{Name: CmdRemove, Args: "<path>",
+			Usage: "remove path from index",
+			flags: commonFlags,
+			parse: removeArgs},
+		{Name: CmdRemoveTree, Args: "<path>",
+			Usage: "remove path and all folders below it from index",
+			flags: commonFlags,
+			parse: removeArgs},
+		{Name: CmdForget, Args: "[start] <keyword>",
+			Usage: "interactively remove results for keyword from index",
+			flags: commonFlags,
+			parse: func(p *Pref, args []string) (err error) {
+				p.Forget, err = queryArgs(args)
+				return
+			}},
+		{Name: CmdPrune,
+			Usage: "remove missing folders from index",
+			flags: func(fs *flag.FlagSet, p *Pref) {
+				commonFlags(fs, p)
+				fs.Var((*duration)(&p.PruneAfter), "after",
+					"keep folders missing for less, e.g. 30d or 12h")
+				fs.BoolVar(&p.DryRun, "dry-run", p.DryRun,
+					"print the folders to remove, change nothing")
+			},
+			parse: noArgs},
+		{Name: CmdPin, Args: "<alias> <path>",
+			Usage: "pin path, a search for exactly alias returns it",
+			flags: commonFlags,
+			parse: func(p *Pref, args []string) error {
+				if len(args) != 2 {
+					return fmt.Errorf("expected alias and path")
+				}
+				p.Pin, p.PinPath = args[0], args[1]
+				return validAlias(p.Pin)
+			}},
+		{Name: CmdUnpin, Args: "<alias|path>",
+			Usage: "unpin the folder with alias or path",
+			flags: commonFlags,
+			parse: func(p *Pref, args []string) error {
+				if len(args) != 1 {
+					return fmt.Errorf("expected alias or path")
+				}
+				p.Pin = args[0]
+				return nil
+			}},
+		{Name: CmdPins,
+			Usage: "list the pinned folders",
+			flags: commonFlags,
+			parse: noArgs},
+		{Name: CmdStats,
+			Usage:   "print statistics of the index",
+			formats: statsFormats,
+			flags: func(fs *flag.FlagSet, p *Pref) {
+				commonFlags(fs, p)
+				fs.StringVar(&p.Format, "format", p.Format,
+					"output format: "+orList(statsFormats))
+				fs.IntVar(&p.Limit, "n", p.Limit, "most visited and recent folders to print")
+			},
+			parse: noArgs},
+		{Name: CmdInit, Args: "[bash|zsh|fish|nushell|elvish]",
+			Usage: "print the shell integration, without shell scan $HOME and add folders",
+			flags: func(fs *flag.FlagSet, p *Pref) {
+				commonFlags(fs, p)
+				initFlags(fs, p)
+			},
+			parse: func(p *Pref, args []string) error {
+				switch len(args) {
+				case 0:
+				case 1:
+					p.InitShell = args[0]
+				default:
+					return fmt.Errorf("expected at most one shell")
+				}
+				return nil
+			}},
+		{Name: CmdComplete, Args: "<partial> [start]",
+			Usage: "print completion candidates for keyword",
+			flags: commonFlags,
+			parse: func(p *Pref, args []string) error {
+				switch len(args) {
+				case 1:
+					p.Complete = Query{Last: args[0]}
+				case 2:
+					p.Complete = Query{Start: args[1], Last: args[0]}
+				default:
+					return fmt.Errorf("expected partial keyword and optional start")
+				}
+				return nil
+			}},
+		{Name: CmdEvaluate, Args: "<visit-log> [ranker...]",
+			Usage: "replay visit-log and compare the rankers",
+			flags: commonFlags,
+			parse: func(p *Pref, args []string) error {
+				if len(args) == 0 {
+					return fmt.Errorf("expected visit-log")
+				}
+				p.Evaluate, p.Args = args[0], args[1:]
+				return nil
+			}},
+		{Name: CmdVersion, Usage: "print maybe version",
+			flags: commonFlags,
+			parse: noArgs},
+		{Name: CmdHelp, Args: "[command]",
+			Usage: "print the help of command",
+			flags: func(*flag.FlagSet, *Pref) {},
+			parse: func(p *Pref, args []string) error {
+				p.Args = args
+				return nil
+			}},
+	}
 }
 
-// Parse flags.
+// Parse the command line. The first argument is the command, or if
+// it starts with -, the flags of former versions, e.g. -search.
 func Parse() Pref {
+	p := defaults()
+	legacyFlags(flag.CommandLine, &p)
+	flag.CommandLine.Usage = Usage
+	args := os.Args[1:]
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		flag.Parse()
+		p.Command = legacyCommand(&p)
+		if c, ok := findCommand(p.Command); ok {
+			if err := checkFormat(c, p.Format); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+		}
+		args = flag.Args()
+		// global flags may precede a command
+		if p.Command != "" || len(args) == 0 {
+			return p
+		}
+	}
+	if err := parseCommand(&p, args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if p.Command == CmdHelp {
+		help(p.Args)
+		os.Exit(0)
+	}
+	return p
+}
+
+// defaults of all preferences.
+func defaults() Pref {
 	homeDir := userHome()
 	// env is only set when run as snap
 	dataDir := os.Getenv("SNAP_USER_COMMON")
 	if dataDir == "" {
 		dataDir = filepath.Join(homeDir, ".local/share/maybe")
 	}
+	var autoPruneAfter duration
+	if err := autoPruneAfter.Set(os.Getenv("MAYBE_PRUNE_AFTER")); err != nil {
+		log.Fatalf("MAYBE_PRUNE_AFTER: %v\n", err)
+	}
+	pruneAfter := DefaultPruneAfter
+	if autoPruneAfter > 0 {
+		pruneAfter = time.Duration(autoPruneAfter)
+	}
+	return Pref{
+		HomeDir:         homeDir,
+		DataDir:         dataDir,
+		MaxEntries:      maxEntries,
+		PruneAfter:      pruneAfter,
+		AutoPruneAfter:  time.Duration(autoPruneAfter),
+		Ranker:          envOr("MAYBE_RANKER", "default"),
+		MinScore:        envUint("MAYBE_MIN_SCORE"),
+		Confidence:      envUint("MAYBE_CONFIDENCE"),
+		Limit:           DefaultLimit,
+		Format:          "text",
+		Color:           "auto",
+		Hyperlink:       "auto",
+		ShellCmd:        "m",
+		ShellListCmd:    "mm",
+		ShellHook:       true,
+		ShellCompletion: true,
+	}
+}
 
-	var p Pref
-	p.HomeDir = homeDir
-	flagDatadirVar(&p.DataDir, "datadir", dataDir, "")
-	flag.StringVar(&p.Add, "add", "", "add path to index")
-	q := flag.String("search", "", "search for keyword")
-	l := flag.String("list", "", "list results for keyword")
-	flag.BoolVar(&p.Init, "init", false, "scan $HOME and add folders (six folder-level deep)")
-	flag.BoolVar(&p.Version, "version", false, "print maybe version")
-	flagMaxentriesVar(&p.MaxEntries, "max-entries", maxEntries, "maximum unique path-entries")
-	verb := flag.Bool("v", false, "verbose")
-	flag.Parse()
-
-	p.Search = queryFrom(*q)
-	p.List = queryFrom(*l)
-	Verbose = *verb
-	return p
+// parseCommand args, starting with the command name.
+func parseCommand(p *Pref, args []string) error {
+	c, ok := findCommand(args[0])
+	if !ok {
+		return fmt.Errorf("unknown command %q, run: %s help",
+			args[0], os.Args[0])
+	}
+	fs := flag.NewFlagSet(c.Name, flag.ExitOnError)
+	fs.Usage = func() { commandUsage(fs, c) }
+	c.flags(fs, p)
+	pos, after := parseInterspersed(fs, args[1:])
+	if c.cmdline {
+		p.Args = after
+	} else {
+		pos = append(pos, after...)
+	}
+	err := c.parse(p, pos)
+	if err == nil {
+		err = checkFormat(c, p.Format)
+	}
+	if err != nil {
+		fmt.Fprintf(fs.Output(), "%s: %v\n", c.Name, err)
+		fs.Usage()
+		os.Exit(2)
+	}
+	p.Command = c.Name
+	return nil
+}
+
+// checkFormat returns an error if c does not support format.
+func checkFormat(c Command, format string) error {
+	if len(c.formats) == 0 {
+		return nil
+	}
+	for _, f := range c.formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q, expected %s", format, orList(c.formats))
+}
+
+// orList joins a as "a, b or c".
+func orList(a []string) string {
+	if len(a) < 2 {
+		return strings.Join(a, "")
+	}
+	return strings.Join(a[:len(a)-1], ", ") + " or " + a[len(a)-1]
+}
+
+func findCommand(name string) (Command, bool) {
+	for _, c := range Commands() {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
+// parseInterspersed flags of fs in args and return the positional
+// arguments, and separately those after --. Flags may follow
+// positional arguments, all arguments after -- are positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) (pos, after []string) {
+	for {
+		fs.Parse(args)
+		rest := fs.Args()
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			return pos, rest
+		}
+		if len(rest) == 0 {
+			return pos, nil
+		}
+		pos = append(pos, rest[0])
+		args = rest[1:]
+	}
+}
+
+func noArgs(p *Pref, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments %v", args)
+	}
+	return nil
+}
+
+func removeArgs(p *Pref, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected one path")
+	}
+	p.Remove = args[0]
+	return nil
+}
+
+// validAlias returns an error if alias can't be searched,
+// e.g. if it is empty or contains whitespace.
+func validAlias(alias string) error {
+	if alias == "" || strings.HasPrefix(alias, "-") ||
+		strings.ContainsAny(alias, "/ \t\n") {
+		return fmt.Errorf("invalid alias %q", alias)
+	}
+	return nil
+}
+
+// queryArgs from one keyword, or start and keyword.
+func queryArgs(args []string) (Query, error) {
+	switch len(args) {
+	case 1:
+		return Query{Last: args[0]}, nil
+	case 2:
+		return Query{Start: args[0], Last: args[1]}, nil
+	}
+	return Query{}, fmt.Errorf("expected keyword and optional start")
+}
+
+// Usage of maybe, the commands.
+func Usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: %s <command> [flags] [arguments]\n\nCommands:\n",
+		os.Args[0])
+	for _, c := range Commands() {
+		fmt.Fprintf(w, "  %-13s%s\n", c.Name, c.Usage)
+	}
+	fmt.Fprintf(w, "\nRun '%s help <command>' for the flags of a command.\n"+
+		"The flags of former versions, e.g. -search, still work.\n",
+		os.Args[0])
+}
+
+// help of the commands in args, or the usage.
+func help(args []string) {
+	if len(args) == 0 {
+		flag.CommandLine.SetOutput(os.Stdout)
+		Usage()
+		return
+	}
+	for _, name := range args {
+		c, ok := findCommand(name)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+			os.Exit(2)
+		}
+		fs := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+		fs.SetOutput(os.Stdout)
+		p := defaults()
+		c.flags(fs, &p)
+		commandUsage(fs, c)
+	}
+}
+
+func commandUsage(fs *flag.FlagSet, c Command) {
+	w := fs.Output()
+	fmt.Fprintf(w, "Usage: %s %s [flags] %s\n\n%s\n", os.Args[0], c.Name,
+		c.Args, c.Usage)
+	if c.Doc != "" {
+		fmt.Fprintf(w, "\n%s\n", c.Doc)
+	}
+	n := 0
+	fs.VisitAll(func(*flag.Flag) { n++ })
+	if n > 0 {
+		fmt.Fprintln(w, "\nFlags:")
+		fs.PrintDefaults()
+	}
+}
+
+// commonFlags of all commands.
+func commonFlags(fs *flag.FlagSet, p *Pref) {
+	fs.Var((*datadir)(&p.DataDir), "datadir", "")
+	fs.Var((*maxentries)(&p.MaxEntries), "max-entries", "maximum unique path-entries")
+	fs.StringVar(&p.Ranker, "ranker", p.Ranker,
+		"comma separated rankers: default, similarity, time, frequency, exec:<cmd>")
+	fs.BoolVar(&Verbose, "v", Verbose, "verbose")
+}
+
+// formatFlags of search and list.
+func formatFlags(fs *flag.FlagSet, p *Pref) {
+	fs.StringVar(&p.Format, "format", p.Format, "output format: "+orList(listFormats))
+	fs.StringVar(&p.Template, "template", p.Template,
+		"text/template or preset for each result: default, full, verbose, visits, path")
+}
+
+// outputFlags of list, the format flags and those of the
+// terminal output.
+func outputFlags(fs *flag.FlagSet, p *Pref) {
+	formatFlags(fs, p)
+	fs.StringVar(&p.Color, "color", p.Color, "highlight matches: auto, always or never")
+	fs.StringVar(&p.Hyperlink, "hyperlink", p.Hyperlink,
+		"link listed paths in the terminal: auto, always or never")
+	fs.IntVar(&Width, "width", Width, "terminal width, detected if 0")
+}
+
+// limitFlags of list.
+func limitFlags(fs *flag.FlagSet, p *Pref) {
+	fs.Var((*count)(&p.Limit), "n", "maximum results to list, 0 is unlimited")
+	fs.Var((*count)(&p.Limit), "limit", "alias for -n")
+	fs.Var((*count)(&p.Offset), "offset", "skip the first list results")
+	fs.Var((*all)(&p.Limit), "all", "list all results")
+}
+
+// scoreFlags of search.
+func scoreFlags(fs *flag.FlagSet, p *Pref) {
+	fs.UintVar(&p.MinScore, "min-score", p.MinScore,
+		"search fails with exit status 3 if the top result has fewer points, default $MAYBE_MIN_SCORE")
+	fs.UintVar(&p.Confidence, "confidence", p.Confidence,
+		"search fails with exit status 3 if the top result leads by fewer points, default $MAYBE_CONFIDENCE")
+}
+
+// initFlags of the shell integration.
+func initFlags(fs *flag.FlagSet, p *Pref) {
+	fs.StringVar(&p.ShellCmd, "cmd", p.ShellCmd, "name of the jump function")
+	fs.StringVar(&p.ShellListCmd, "list-cmd", p.ShellListCmd, "name of the list function")
+	fs.BoolVar(&p.ShellHook, "hook", p.ShellHook, "add visited folders to maybe")
+	fs.BoolVar(&p.ShellCompletion, "completion", p.ShellCompletion,
+		"complete the functions and maybe, in bash, zsh and fish")
+}
+
+// legacy flags, the commands of former versions.
+var legacy struct {
+	search, list, pick, complete string
+	removeTree, forget, pin      string
+	init, version, prune, stats  bool
+	pins                         bool
+}
+
+// legacyFlags registers the flags of former versions on fs,
+// the commands are flags, e.g. -search instead of search.
+func legacyFlags(fs *flag.FlagSet, p *Pref) {
+	commonFlags(fs, p)
+	outputFlags(fs, p)
+	limitFlags(fs, p)
+	scoreFlags(fs, p)
+	fs.StringVar(&p.Add, "add", "", "add path to index")
+	fs.StringVar(&p.Remove, "remove", "", "remove path from index")
+	fs.StringVar(&legacy.removeTree, "remove-tree", "",
+		"remove path and all folders below it from index")
+	fs.StringVar(&legacy.forget, "forget", "",
+		"interactively remove results for keyword from index")
+	fs.BoolVar(&legacy.prune, "prune", false, "remove missing folders from index")
+	fs.Var(durations{&p.AutoPruneAfter, &p.PruneAfter}, "prune-after",
+		"time a folder may be missing before prune removes it, add prunes once a day if set, e.g. 30d")
+	fs.BoolVar(&p.DryRun, "dry-run", false, "print the folders prune would remove")
+	fs.BoolVar(&legacy.stats, "stats", false, "print statistics of the index")
+	fs.StringVar(&legacy.pin, "pin", "",
+		"pin the path argument, a search for exactly alias returns it")
+	fs.StringVar(&p.Pin, "unpin", "", "unpin the folder with alias or path")
+	fs.BoolVar(&legacy.pins, "pins", false, "list the pinned folders")
+	fs.StringVar(&legacy.search, "search", "", "search for keyword")
+	fs.StringVar(&legacy.list, "list", "", "list results for keyword")
+	fs.StringVar(&legacy.pick, "pick", "", "interactively pick a result for keyword")
+	fs.StringVar(&legacy.complete, "complete", "",
+		"print completion candidates for keyword, optionally after a start keyword argument")
+	fs.BoolVar(&legacy.init, "init", false, "scan $HOME and add folders (six folder-level deep)")
+	fs.BoolVar(&legacy.version, "version", false, "print maybe version")
+	fs.IntVar(&p.Select, "select", 0, "print the n-th result of the last list")
+	fs.StringVar(&p.Evaluate, "evaluate", "",
+		"replay visit-log and compare the rankers given as arguments")
+}
+
+// legacyCommand returns the command of the parsed legacy flags,
+// checked in the order of former versions.
+func legacyCommand(p *Pref) string {
+	var completing bool
+	flag.Visit(func(f *flag.Flag) {
+		completing = completing || f.Name == "complete"
+	})
+	switch {
+	case legacy.version:
+		return CmdVersion
+	case p.Evaluate != "":
+		p.Args = flag.Args()
+		return CmdEvaluate
+	case completing:
+		// an empty keyword is valid, the start follows it
+		p.Complete = Query{Start: flag.Arg(0), Last: legacy.complete}
+		return CmdComplete
+	case legacy.init:
+		return CmdInit
+	case p.Add != "":
+		return CmdAdd
+	case p.Remove != "":
+		return CmdRemove
+	case legacy.removeTree != "":
+		p.Remove = legacy.removeTree
+		return CmdRemoveTree
+	case legacy.prune:
+		return CmdPrune
+	case legacy.stats:
+		return CmdStats
+	case legacy.pin != "":
+		p.Pin, p.PinPath = legacy.pin, flag.Arg(0)
+		if err := validAlias(p.Pin); err != nil || p.PinPath == "" {
+			fmt.Fprintln(os.Stderr, "usage: -pin <alias> <path>")
+			os.Exit(2)
+		}
+		return CmdPin
+	case p.Pin != "":
+		return CmdUnpin
+	case legacy.pins:
+		return CmdPins
+	case strings.TrimSpace(legacy.forget) != "":
+		p.Forget = queryFrom(legacy.forget)
+		return CmdForget
+	case strings.TrimSpace(legacy.search) != "":
+		p.Search = queryFrom(legacy.search)
+		return CmdSearch
+	case strings.TrimSpace(legacy.list) != "":
+		p.List = queryFrom(legacy.list)
+		return CmdList
+	case p.Select != 0:
+		return CmdSelect
+	case strings.TrimSpace(legacy.pick) != "":
+		p.Pick = queryFrom(legacy.pick)
+		return CmdPick
+	}
+	return ""
+}
+
+// LegacyFlags of former versions, the superset of all command flags.
+func LegacyFlags() *flag.FlagSet {
+	return flag.CommandLine
 }
 
 // Query object
@@ -70,6 +698,24 @@ func (q Query) String() string {
 	return fmt.Sprintf("{start: %s  last: %s}", q.Start, q.Last)
 }
 
+// Text returns the query as typed by the user.
+func (q Query) Text() string {
+	return strings.TrimSpace(q.Start + " " + q.Last)
+}
+
+// NewQuery from whitespace separated words, the first of two words
+// is the start, the last word the query.
+func NewQuery(s string) Query {
+	a := strings.Fields(s)
+	switch len(a) {
+	case 0:
+		return Query{}
+	case 1:
+		return Query{Last: a[0]}
+	}
+	return Query{Start: a[0], Last: a[len(a)-1]}
+}
+
 func queryFrom(s string) Query {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -81,6 +727,29 @@ func queryFrom(s string) Query {
 	return Query{Last: s}
 }
 
+// envOr returns the value of the environment variable key,
+// or def if unset.
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// envUint returns the number in the environment variable key,
+// or 0 if unset.
+func envUint(key string) uint {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseUint(v, 10, 0)
+	if err != nil {
+		log.Fatalf("%s: %v\n", key, err)
+	}
+	return uint(n)
+}
+
 func userHome() string {
 	user, err := user.Current()
 	if err != nil {
@@ -94,11 +763,6 @@ func userHome() string {
 
 type maxentries int
 
-func flagMaxentriesVar(p *int, name string, value int, usage string) {
-	*p = value
-	flag.CommandLine.Var((*maxentries)(p), name, usage)
-}
-
 func (m *maxentries) String() string { return strconv.Itoa(int(*m)) }
 
 func (m *maxentries) Set(s string) error {
@@ -112,11 +776,6 @@ func (m *maxentries) Set(s string) error {
 
 type datadir string
 
-func flagDatadirVar(p *string, name string, value string, usage string) {
-	*p = value
-	flag.CommandLine.Var((*datadir)(p), name, usage)
-}
-
 func (m *datadir) String() string { return string(*m) }
 
 func (m *datadir) Set(s string) error {
@@ -126,3 +785,93 @@ func (m *datadir) Set(s string) error {
 	*m = datadir(s)
 	return nil
 }
+
+// count is a non-negative number, e.g. of results.
+type count int
+
+func (c *count) String() string { return strconv.Itoa(int(*c)) }
+
+func (c *count) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return fmt.Errorf("negative value %d", n)
+	}
+	*c = count(n)
+	return nil
+}
+
+// all sets the limit to 0, unlimited.
+type all int
+
+func (a *all) String() string { return "false" }
+
+func (a *all) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if b {
+		*a = 0
+	}
+	return err
+}
+
+func (a *all) IsBoolFlag() bool { return true }
+
+// duration of a time.ParseDuration string, or whole days, e.g. 30d.
+// Empty is 0.
+type duration time.Duration
+
+func (d *duration) String() string {
+	if d == nil || *d == 0 {
+		return ""
+	}
+	day := duration(24 * time.Hour)
+	if *d%day == 0 {
+		return fmt.Sprintf("%dd", *d/day)
+	}
+	return time.Duration(*d).String()
+}
+
+func (d *duration) Set(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		*d = 0
+		return nil
+	}
+	if n := strings.TrimSuffix(s, "d"); n != s {
+		days, err := strconv.ParseUint(n, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid days %q", s)
+		}
+		*d = duration(time.Duration(days) * 24 * time.Hour)
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil || v < 0 {
+		return fmt.Errorf("invalid duration %q", s)
+	}
+	*d = duration(v)
+	return nil
+}
+
+// durations sets all durations to the same duration value.
+type durations []*time.Duration
+
+func (a durations) String() string {
+	if len(a) == 0 {
+		return ""
+	}
+	return (*duration)(a[0]).String()
+}
+
+func (a durations) Set(s string) error {
+	var d duration
+	if err := d.Set(s); err != nil {
+		return err
+	}
+	for _, p := range a {
+		*p = time.Duration(d)
+	}
+	return nil
+}
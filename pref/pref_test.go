@@ -0,0 +1,116 @@
+package pref
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLimitFlags(t *testing.T) {
+	tt := []struct {
+		name          string
+		args          []string
+		limit, offset int
+		err           bool
+	}{
+		{name: "default", limit: DefaultLimit},
+		{name: "n", args: []string{"-n", "3"}, limit: 3},
+		{name: "n 0", args: []string{"-n", "0"}, limit: 0},
+		{name: "limit", args: []string{"-limit", "5"}, limit: 5},
+		{name: "all", args: []string{"-n", "3", "-all"}, limit: 0},
+		{name: "offset", args: []string{"-offset", "20"}, limit: DefaultLimit,
+			offset: 20},
+		{name: "negative n", args: []string{"-n", "-1"}, err: true},
+		{name: "negative limit", args: []string{"-limit", "-1"}, err: true},
+		{name: "negative offset", args: []string{"-offset", "-2"}, err: true},
+		{name: "no number", args: []string{"-n", "x"}, err: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, register := range []func(*flag.FlagSet, *Pref){
+				limitFlags, legacyFlags} {
+				fs := flag.NewFlagSet("list", flag.ContinueOnError)
+				fs.SetOutput(io.Discard)
+				p := Pref{Limit: DefaultLimit}
+				register(fs, &p)
+				err := fs.Parse(tc.args)
+				if tc.err {
+					if err == nil {
+						t.Errorf("exp error for %v", tc.args)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if p.Limit != tc.limit || p.Offset != tc.offset {
+					t.Errorf("exp limit %d offset %d, got %d %d",
+						tc.limit, tc.offset, p.Limit, p.Offset)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	tt := []struct {
+		cmd, format string
+		exp         string // error, empty if valid
+	}{
+		{cmd: CmdList, format: "nul"},
+		{cmd: CmdSearch, format: "tsv"},
+		{cmd: CmdStats, format: "json"},
+		{cmd: CmdStats, format: "tsv",
+			exp: `unknown format "tsv", expected text or json`},
+		{cmd: CmdList, format: "xml",
+			exp: `unknown format "xml", expected text, json, tsv or nul`},
+		{cmd: CmdAdd, format: "xml"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.cmd+" "+tc.format, func(t *testing.T) {
+			c, ok := findCommand(tc.cmd)
+			if !ok {
+				t.Fatalf("unknown command %q", tc.cmd)
+			}
+			var res string
+			if err := checkFormat(c, tc.format); err != nil {
+				res = err.Error()
+			}
+			if res != tc.exp {
+				t.Errorf("exp %q, got %q", tc.exp, res)
+			}
+		})
+	}
+}
+
+// The legacy flag list of the README is the output of PrintDefaults.
+func TestReadmeLegacyFlags(t *testing.T) {
+	readme, err := os.ReadFile("../README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"MAYBE_RANKER", "MAYBE_PRUNE_AFTER",
+		"MAYBE_MIN_SCORE", "MAYBE_CONFIDENCE", "SNAP_USER_COMMON"} {
+		if v, ok := os.LookupEnv(key); ok {
+			defer os.Setenv(key, v)
+			os.Unsetenv(key)
+		}
+	}
+	p := defaults()
+	fs := flag.NewFlagSet("maybe", flag.ContinueOnError)
+	legacyFlags(fs, &p)
+	var b bytes.Buffer
+	fs.SetOutput(&b)
+	fs.PrintDefaults()
+	exp := strings.ReplaceAll(b.String(), p.HomeDir, "$HOME")
+	exp = strings.ReplaceAll(exp, "\n    \t", "\n          ")
+	exp = strings.ReplaceAll(exp, "\t", "    ")
+	exp = strings.ReplaceAll(exp, "\n  -", "\n    -")
+	exp = "  " + exp
+	if !strings.Contains(string(readme), exp) {
+		t.Errorf("README legacy flags differ from PrintDefaults, exp:\n%s", exp)
+	}
+}
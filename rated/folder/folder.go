@@ -11,6 +11,10 @@ type Folder struct {
 	Path        string
 	UpdateCount uint32      // counts how often the folder has been updated
 	Times       []time.Time // last MaxTimesEntries updates
+	// MissingSince is the time the folder was first found missing,
+	// zero if it existed when last checked.
+	MissingSince time.Time
+	Pin          string // alias of a pinned folder, empty if not pinned
 }
 
 // New folder object.
@@ -44,6 +48,6 @@ func CheckerFn() ResourceCheckerFn {
 			return false
 		}
 		fi, err := os.Stat(path)
-		return !(os.IsNotExist(err) || !fi.IsDir())
+		return err == nil && fi.IsDir()
 	})
 }
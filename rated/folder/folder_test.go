@@ -1,6 +1,8 @@
 package folder
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -27,3 +29,28 @@ func TestNewFolder(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckerFn(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "afile")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	tt := []struct {
+		name, path string
+		exp        bool
+	}{
+		{name: "dir", path: dir, exp: true},
+		{name: "file", path: file},
+		{name: "missing", path: filepath.Join(dir, "nope")},
+		{name: "not a dir", path: filepath.Join(file, "sub")},
+		{name: "empty", path: " "},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if res := CheckerFn()(tc.path); res != tc.exp {
+				t.Errorf("exp %t, got %t", tc.exp, res)
+			}
+		})
+	}
+}
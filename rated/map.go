@@ -1,8 +1,12 @@
 package rated
 
 import (
+	"fmt"
+	"os"
 	"runtime"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/thibran/maybe/classify"
 	"github.com/thibran/maybe/rated/folder"
@@ -13,10 +17,15 @@ type Map map[string]*folder.Folder
 
 type sorterFn func(a Slice)
 
-// Search for s and sort results.
-func (m *Map) Search(query string, sort sorterFn) Slice {
+// Search for s, rate results with rk and sort them. Only a
+// failing classify.BatchRanker returns an error.
+func (m *Map) Search(query string, rk classify.Ranker, c classify.Context,
+	sort sorterFn) (Slice, error) {
 	if len(*m) == 0 {
-		return Slice{}
+		return Slice{}, nil
+	}
+	if br, ok := rk.(classify.BatchRanker); ok {
+		return m.searchBatch(query, br, c, sort)
 	}
 	var wg sync.WaitGroup
 	workers := runtime.NumCPU()
@@ -36,7 +45,7 @@ func (m *Map) Search(query string, sort sorterFn) Slice {
 	for i := 0; i < workers; i++ {
 		go func() {
 			for f := range tasks {
-				rf, err := New(f, query)
+				rf, err := New(f, query, rk, c)
 				if err != nil || rf.Points() == classify.NoMatch {
 					continue
 				}
@@ -45,7 +54,29 @@ func (m *Map) Search(query string, sort sorterFn) Slice {
 			wg.Done()
 		}()
 	}
-	return collectResults(results, sort)
+	return collectResults(results, sort), nil
+}
+
+// searchBatch rates all folders of m at once.
+func (m *Map) searchBatch(query string, br classify.BatchRanker,
+	c classify.Context, sort sorterFn) (Slice, error) {
+	fs := make([]*folder.Folder, 0, len(*m))
+	for _, f := range *m {
+		fs = append(fs, f)
+	}
+	ratings, err := br.RateAll(fs, query, c)
+	if err != nil {
+		return nil, fmt.Errorf("rated.Search - %v", err)
+	}
+	var a Slice
+	for i, r := range ratings {
+		if r == nil || r.Points() == classify.NoMatch {
+			continue
+		}
+		a = append(a, &Rated{Folder: fs[i], Rating: r})
+	}
+	sort(a)
+	return a, nil
 }
 
 func createTasks(m Map) <-chan *folder.Folder {
@@ -68,12 +99,19 @@ func collectResults(c <-chan *Rated, sort sorterFn) Slice {
 	return a
 }
 
-// RemoveOldest folders from map and keep newest n entries.
-func (m *Map) RemoveOldest(n int) {
+// RemoveOldest folders from map and keep newest n entries,
+// relative to time now. Pinned folders are always kept.
+func (m *Map) RemoveOldest(n int, now time.Time) {
 	// to time-folders
 	var a TimeSlice
+	var pinned []*folder.Folder
+	c := classify.Context{Now: now}
 	for _, f := range *m {
-		if rf, err := New(f, ""); err == nil {
+		if f.Pin != "" {
+			pinned = append(pinned, f)
+			continue
+		}
+		if rf, err := New(f, "", classify.Time{}, c); err == nil {
 			a = append(a, rf)
 		}
 	}
@@ -82,9 +120,36 @@ func (m *Map) RemoveOldest(n int) {
 	if len(a) > n {
 		a = a[:n]
 	}
-	m2 := make(Map, len(a))
+	m2 := make(Map, len(a)+len(pinned))
 	for _, rf := range a {
 		m2[rf.Path] = rf.Folder
 	}
+	for _, f := range pinned {
+		m2[f.Path] = f
+	}
 	*m = m2
 }
+
+// Remove the folder path from map, returns false if unknown.
+func (m *Map) Remove(path string) bool {
+	if _, ok := (*m)[path]; !ok {
+		return false
+	}
+	delete(*m, path)
+	return true
+}
+
+// RemoveTree removes the folder root and all folders below it
+// from map, returns the number of removed folders.
+func (m *Map) RemoveTree(root string) int {
+	root = strings.TrimSuffix(root, string(os.PathSeparator))
+	prefix := root + string(os.PathSeparator)
+	var n int
+	for path := range *m {
+		if path == root || strings.HasPrefix(path, prefix) {
+			delete(*m, path)
+			n++
+		}
+	}
+	return n
+}
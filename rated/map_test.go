@@ -30,7 +30,7 @@ func TestRemoveOldest(t *testing.T) {
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			m := Map{f1.Path: f1, f2.Path: f2, f3.Path: f3}
-			m.RemoveOldest(tc.keepValues)
+			m.RemoveOldest(tc.keepValues, now)
 			if len(m) != tc.resultLen {
 				t.Fatalf("expected len(res) %d, got %v", tc.keepValues, len(m))
 			}
@@ -40,3 +40,62 @@ func TestRemoveOldest(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoveOldest_pinned(t *testing.T) {
+	now := time.Now()
+	old := folder.New("/home/old", now.Add(-time.Hour*24*400))
+	old.Pin = "old"
+	m := Map{old.Path: old}
+	for _, p := range []string{"/home/a", "/home/b"} {
+		m[p] = folder.New(p, now)
+	}
+	m.RemoveOldest(1, now)
+	if _, ok := m[old.Path]; !ok || len(m) != 2 {
+		t.Fatalf("exp pinned and newest folder kept, got %v", m)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	f := folder.New("/home/foo", time.Now())
+	m := Map{f.Path: f}
+	if m.Remove("/home/bar") {
+		t.Fatal("removed unknown folder")
+	}
+	if !m.Remove(f.Path) || len(m) != 0 {
+		t.Fatalf("exp empty map, got %v", m)
+	}
+}
+
+func TestRemoveTree(t *testing.T) {
+	now := time.Now()
+	tt := []struct {
+		name, root string
+		exp        int
+		kept       []string
+	}{
+		{name: "tree", root: "/home/foo", exp: 3,
+			kept: []string{"/home", "/home/foobar"}},
+		{name: "trailing separator", root: "/home/foo/", exp: 3,
+			kept: []string{"/home", "/home/foobar"}},
+		{name: "leaf", root: "/home/foo/a/b", exp: 1},
+		{name: "root", root: "/", exp: 5},
+		{name: "unknown", root: "/tmp", exp: 0},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := make(Map)
+			for _, p := range []string{"/home", "/home/foo", "/home/foo/a",
+				"/home/foo/a/b", "/home/foobar"} {
+				m[p] = folder.New(p, now)
+			}
+			if n := m.RemoveTree(tc.root); n != tc.exp {
+				t.Fatalf("exp %d, got %d", tc.exp, n)
+			}
+			for _, p := range tc.kept {
+				if _, ok := m[p]; !ok {
+					t.Errorf("%s should be in the map", p)
+				}
+			}
+		})
+	}
+}
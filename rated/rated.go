@@ -3,13 +3,11 @@ package rated
 import (
 	"fmt"
 	"os"
-	"path"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/thibran/maybe/classify"
-	"github.com/thibran/maybe/pref"
 	"github.com/thibran/maybe/rated/folder"
 	"github.com/thibran/maybe/util"
 )
@@ -18,16 +16,30 @@ import (
 type Rated struct {
 	*folder.Folder
 	*classify.Rating
+	DisplayPath string // shortened path, set by CutLongPaths
+	Suffix      string // shortest unique trailing path, set by CutLongPaths
+	Shortened   []bool // shortened segments of DisplayPath, set by CutLongPaths
+	// shortened segments of Suffix, set by CutLongPaths
+	SuffixShortened []bool
+}
+
+// Display returns the DisplayPath, or Path if not set.
+func (rf *Rated) Display() string {
+	if rf.DisplayPath != "" {
+		return rf.DisplayPath
+	}
+	return rf.Path
 }
 
 const osSep = string(os.PathSeparator)
 
-// New creates a new rated folder object.
-func New(f *folder.Folder, query string) (*Rated, error) {
+// New creates a new rated folder object, rated by rk.
+func New(f *folder.Folder, query string, rk classify.Ranker,
+	c classify.Context) (*Rated, error) {
 	if f == nil {
 		return nil, fmt.Errorf("rated.New - *Folder is nil")
 	}
-	r, err := classify.NewRating(query, path.Base(f.Path), f.Times...)
+	r, err := rk.Rate(f, query, c)
 	if err != nil {
 		return nil, fmt.Errorf("rated.New - %v", err)
 	}
@@ -40,10 +52,14 @@ func New(f *folder.Folder, query string) (*Rated, error) {
 // Slice is an alias for Slice.
 type Slice []*Rated
 
-// Sort a RatedFolders.
+// Sort a RatedFolders. Base name matches come before parent
+// segment matches, regardless of their points.
 func (rs Slice) Sort() {
 	var pi, pj uint
 	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Depth != rs[j].Depth {
+			return rs[i].Depth < rs[j].Depth
+		}
 		pi = rs[i].Points()
 		pj = rs[j].Points()
 		if pi == pj {
@@ -56,6 +72,30 @@ func (rs Slice) Sort() {
 	})
 }
 
+// Ambiguous returns true if the first entry does not lead the
+// second entry by at least lead points. A lead of 0 is never ambiguous,
+// neither is a second entry matching a deeper path segment.
+func (rs Slice) Ambiguous(lead uint) bool {
+	return lead > 0 && rs.Contenders(lead) > 1
+}
+
+// Contenders returns the number of leading entries, the first
+// included, which the first entry does not lead by at least lead
+// points, matching the same path segment.
+func (rs Slice) Contenders(lead uint) int {
+	if len(rs) == 0 {
+		return 0
+	}
+	n := 1
+	for _, rf := range rs[1:] {
+		if rf.Depth != rs[0].Depth || rs[0].Points() >= rf.Points()+lead {
+			break
+		}
+		n++
+	}
+	return n
+}
+
 // FilterInPathOf returns only entries where the path
 // contains the start-string in the non-last segment.
 // When start is empty nothing is changed.
@@ -85,24 +125,25 @@ func (rs *Slice) FilterInPathOf(start string) {
 	*rs = a
 }
 
-// CutLongPaths if too long.
-func (rs *Slice) CutLongPaths(cutLong bool) {
-	if !cutLong {
-		return
+// CutLongPaths sets the DisplayPath of all entries, shortened if
+// longer than the terminal width minus margin, the columns printed
+// besides the path. The shortest unique trailing path of each entry
+// is kept intact, if possible, and stored as Suffix.
+func (rs *Slice) CutLongPaths(margin int) {
+	maxLineLen := util.TermWidth() - margin
+	var paths []string
+	for _, rf := range *rs {
+		paths = append(paths, rf.Path)
 	}
-	var a Slice
-	// use terminal width, when possible
-	maxLineLen := 64
-	if w, err := util.TermWidth(); err == nil {
-		if pref.Verbose && w-16 > 0 {
-			maxLineLen = w - 16
-		} else if !pref.Verbose && w-10 > 0 {
-			maxLineLen = w - 10
+	for i, n := range util.UniqueSuffixes(paths) {
+		rf := (*rs)[i]
+		rf.Suffix, rf.SuffixShortened = util.ShortenPathTail(
+			util.Suffix(rf.Path, n), maxLineLen, n)
+		// keep at least the last two segments
+		if n < 2 {
+			n = 2
 		}
+		rf.DisplayPath, rf.Shortened = util.ShortenPathTail(rf.Path,
+			maxLineLen, n)
 	}
-	for _, rf := range *rs {
-		rf.Path = util.ShortenPath(rf.Path, maxLineLen)
-		a = append(a, rf)
-	}
-	*rs = a
 }
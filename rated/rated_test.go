@@ -1,10 +1,12 @@
 package rated
 
 import (
+	"os"
 	"testing"
 	"time"
 
 	"github.com/thibran/maybe/classify"
+	"github.com/thibran/maybe/pref"
 	"github.com/thibran/maybe/rated/folder"
 )
 
@@ -84,3 +86,97 @@ func TestFilterInPathOf(t *testing.T) {
 		})
 	}
 }
+
+func TestAmbiguous(t *testing.T) {
+	ratedFn := func(points uint) *Rated {
+		return &Rated{Rating: &classify.Rating{SimilarityPoints: points}}
+	}
+	tt := []struct {
+		name string
+		lead uint
+		exp  bool
+		Slice
+	}{
+		{name: "disabled", lead: 0, exp: false,
+			Slice: Slice{ratedFn(50), ratedFn(50)}},
+		{name: "tie", lead: 1, exp: true,
+			Slice: Slice{ratedFn(50), ratedFn(50)}},
+		{name: "small lead", lead: 10, exp: true,
+			Slice: Slice{ratedFn(50), ratedFn(41)}},
+		{name: "clear lead", lead: 10, exp: false,
+			Slice: Slice{ratedFn(50), ratedFn(40)}},
+		{name: "single result", lead: 10, exp: false,
+			Slice: Slice{ratedFn(50)}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if res := tc.Ambiguous(tc.lead); res != tc.exp {
+				t.Errorf("%s - exp %v, got %v", tc.name, tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestContenders(t *testing.T) {
+	ratedFn := func(points, depth uint) *Rated {
+		return &Rated{Rating: &classify.Rating{SimilarityPoints: points,
+			Depth: depth}}
+	}
+	tt := []struct {
+		name string
+		lead uint
+		exp  int
+		Slice
+	}{
+		{name: "empty", lead: 10, exp: 0},
+		{name: "disabled", lead: 0, exp: 1,
+			Slice: Slice{ratedFn(50, 0), ratedFn(50, 0)}},
+		{name: "ties", lead: 1, exp: 3, Slice: Slice{ratedFn(50, 0),
+			ratedFn(50, 0), ratedFn(50, 0), ratedFn(49, 0)}},
+		{name: "window", lead: 10, exp: 2, Slice: Slice{ratedFn(50, 0),
+			ratedFn(41, 0), ratedFn(40, 0)}},
+		{name: "deeper segment", lead: 10, exp: 1, Slice: Slice{ratedFn(50, 0),
+			ratedFn(50, 1)}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if res := tc.Contenders(tc.lead); res != tc.exp {
+				t.Errorf("exp %d, got %d", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestCutLongPaths(t *testing.T) {
+	width := pref.Width
+	defer func() { pref.Width = width }()
+	pref.Width = 26 // max. path length 16
+	home := os.Getenv("HOME")
+	defer os.Setenv("HOME", home)
+	os.Setenv("HOME", "/nonexistent")
+	now := time.Now()
+	a := Slice{
+		{Folder: folder.New("/home/tux/src/svc1/api/src", now)},
+		{Folder: folder.New("/home/tux/src/svc2/api/src", now)},
+		{Folder: folder.New("/tmp/foo", now)},
+	}
+	a.CutLongPaths(10)
+	tt := []struct {
+		display, suffix string
+	}{
+		{display: "...svc1/api/src", suffix: "svc1/api/src"},
+		{display: "...svc2/api/src", suffix: "svc2/api/src"},
+		{display: "/tmp/foo", suffix: "foo"},
+	}
+	for i, tc := range tt {
+		if a[i].DisplayPath != tc.display {
+			t.Errorf("exp %q, got %q", tc.display, a[i].DisplayPath)
+		}
+		if a[i].Suffix != tc.suffix {
+			t.Errorf("exp %q, got %q", tc.suffix, a[i].Suffix)
+		}
+		if a[i].Path == a[i].DisplayPath && i < 2 {
+			t.Errorf("path should be shortened: %q", a[i].Path)
+		}
+	}
+}
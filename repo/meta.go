@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// meta data of the repo, saved in a sidecar file next to the data
+// file, so the data file format stays unchanged.
+type meta struct {
+	LastPrune time.Time `json:"last_prune"`
+	Evictions uint64    `json:"evictions"` // folders removed by RemoveOldest
+}
+
+func (r *Repo) metaPath() string { return r.dataDir + ".meta" }
+
+// saveMeta to the sidecar file.
+func (r *Repo) saveMeta() error {
+	b, err := json.MarshalIndent(r.meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.metaPath(), append(b, '\n'), 0660)
+}
+
+// loadMeta from the sidecar file, a missing file is no error.
+func (r *Repo) loadMeta() error {
+	b, err := ioutil.ReadFile(r.metaPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &r.meta)
+}
@@ -0,0 +1,27 @@
+package repo
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMeta(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maybe.data")
+	r := New(path, 10)
+	if err := r.loadMeta(); err != nil {
+		t.Fatalf("missing meta file: %v", err)
+	}
+	now := time.Date(2020, time.May, 1, 12, 0, 0, 0, time.UTC)
+	r.meta.LastPrune = now
+	if err := r.Save(); err != nil {
+		t.Fatal(err)
+	}
+	r = New(path, 10)
+	if err := r.loadFile(); err != nil {
+		t.Fatal(err)
+	}
+	if !r.meta.LastPrune.Equal(now) {
+		t.Fatalf("exp %v, got %v", now, r.meta.LastPrune)
+	}
+}
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"sort"
+
+	"github.com/thibran/maybe/classify"
+	"github.com/thibran/maybe/rated"
+	"github.com/thibran/maybe/rated/folder"
+)
+
+// Pinned folder and its alias.
+type Pinned struct {
+	Alias, Path string
+}
+
+// Pin path with alias, a search for exactly alias returns path.
+// An unknown path is added, an alias or path pinned before is
+// unpinned first.
+func (r *Repo) Pin(alias, path string) {
+	r.Unpin(alias)
+	f, ok := r.m[path]
+	if !ok {
+		r.updateOrAdd(path, r.now(), true)
+		f = r.m[path]
+	}
+	f.Pin = alias
+}
+
+// Unpin the folder with alias or path s, returns false if none
+// is pinned.
+func (r *Repo) Unpin(s string) bool {
+	if f, ok := r.m[s]; ok && f.Pin != "" {
+		f.Pin = ""
+		return true
+	}
+	if f, ok := r.pinned(s); ok {
+		f.Pin = ""
+		return true
+	}
+	return false
+}
+
+// Pins returns the pinned folders sorted by alias.
+func (r *Repo) Pins() []Pinned {
+	var a []Pinned
+	for _, f := range r.m {
+		if f.Pin != "" {
+			a = append(a, Pinned{Alias: f.Pin, Path: f.Path})
+		}
+	}
+	sort.Slice(a, func(i, j int) bool { return a[i].Alias < a[j].Alias })
+	return a
+}
+
+// pinned folder with alias.
+func (r *Repo) pinned(alias string) (*folder.Folder, bool) {
+	if alias == "" {
+		return nil, false
+	}
+	for _, f := range r.m {
+		if f.Pin == alias {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// SearchPin returns the existing folder pinned with alias, rated as
+// equal to the query.
+func (r *Repo) SearchPin(ch ResourceChecker, alias string) (*rated.Rated, bool) {
+	f, ok := r.pinned(alias)
+	if !ok || !ch.DoesExist(f.Path) {
+		return nil, false
+	}
+	return &rated.Rated{Folder: f,
+		Rating: &classify.Rating{SimilarityPoints: classify.StrEquals}}, true
+}
@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thibran/maybe/pref"
+	"github.com/thibran/maybe/rated/folder"
+)
+
+func TestPin(t *testing.T) {
+	now := time.Now()
+	r := New("/baz/bar/zot", 10)
+	r.updateOrAdd("/home/proj", now, false)
+	r.updateOrAdd("/home/project", now, false)
+	r.updateOrAdd("/home/project", now, false)
+	r.Pin("p", "/home/proj")
+	r.Pin("old", "/gone/old")
+	doesExist := folder.ResourceCheckerFn(func(path string) bool {
+		return !strings.HasPrefix(path, "/gone")
+	})
+	tt := []struct {
+		name string
+		q    pref.Query
+		exp  string
+	}{
+		{name: "alias", q: pref.Query{Last: "p"}, exp: "[/home/proj]"},
+		{name: "keyword", q: pref.Query{Last: "proj"},
+			exp: "[/home/project /home/proj]"},
+		{name: "with start", q: pref.Query{Start: "home", Last: "p"},
+			exp: "[/home/project /home/proj]"},
+		{name: "missing", q: pref.Query{Last: "old"}, exp: "[]"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := r.Candidates(doesExist, tc.q, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var a []string
+			for _, rf := range res {
+				a = append(a, rf.Path)
+			}
+			if res := fmt.Sprint(a); res != tc.exp {
+				t.Errorf("exp %s, got %s", tc.exp, res)
+			}
+		})
+	}
+	if rf, ok := r.SearchPin(doesExist, "p"); !ok || rf.Path != "/home/proj" {
+		t.Errorf("exp /home/proj pinned with p, got %v", rf)
+	}
+	if _, ok := r.SearchPin(doesExist, "old"); ok {
+		t.Error("exp missing pinned folder not to be found")
+	}
+	if a := r.Prune(doesExist, 0, false); len(a) != 0 {
+		t.Errorf("pinned folders should not be pruned, got %v", a)
+	}
+	if res := fmt.Sprint(r.Pins()); res != "[{old /gone/old} {p /home/proj}]" {
+		t.Errorf("unexpected pins %s", res)
+	}
+}
+
+func TestUnpin(t *testing.T) {
+	r := New("/baz/bar/zot", 10)
+	r.Pin("p", "/home/proj")
+	r.Pin("p", "/home/project")
+	if res := fmt.Sprint(r.Pins()); res != "[{p /home/project}]" {
+		t.Fatalf("exp alias moved, got %s", res)
+	}
+	r.Pin("q", "/home/project")
+	if !r.Unpin("/home/project") || r.Unpin("q") || r.Unpin("/home/proj") {
+		t.Fatal("exp only /home/project unpinned")
+	}
+	r.Pin("p", "/home/proj")
+	if !r.Unpin("p") || len(r.Pins()) != 0 {
+		t.Fatalf("exp no pins, got %v", r.Pins())
+	}
+}
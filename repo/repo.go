@@ -7,16 +7,22 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/thibran/maybe/classify"
 	"github.com/thibran/maybe/pref"
 	"github.com/thibran/maybe/rated"
 	"github.com/thibran/maybe/rated/folder"
 	"github.com/thibran/maybe/util"
 )
 
-const osSep = string(os.PathSeparator)
+const (
+	osSep = string(os.PathSeparator)
+	// AutoPruneInterval is the minimal time between automatic prunes.
+	AutoPruneInterval = 24 * time.Hour
+)
 
 var (
 	// ErrNoResult - search has no result
@@ -30,6 +36,9 @@ type Repo struct {
 	m          rated.Map
 	dataDir    string
 	maxEntries int
+	rk         classify.Ranker
+	now        func() time.Time
+	meta       meta
 }
 
 // New repo object.
@@ -38,9 +47,17 @@ func New(path string, maxEntries int) *Repo {
 		m:          make(rated.Map),
 		dataDir:    path,
 		maxEntries: maxEntries,
+		rk:         classify.Default,
+		now:        time.Now,
 	}
 }
 
+// SetRanker used by Search and List.
+func (r *Repo) SetRanker(rk classify.Ranker) { r.rk = rk }
+
+// SetClock used to rate and evict folders, default is time.Now.
+func (r *Repo) SetClock(now func() time.Time) { r.now = now }
+
 // Walk adds directories from root, for count
 // of Repo.maxEntries, osWalker.lvlDeep.
 func (r *Repo) Walk(root string) {
@@ -88,8 +105,9 @@ func (r *Repo) updateOrAdd(path string, t time.Time, subfolder bool) {
 		r.m[path] = folder.New(path, t)
 
 		// guarantee folder limit holds
-		if len(r.m) > r.maxEntries {
-			r.m.RemoveOldest(r.maxEntries - r.maxEntries/3)
+		if n := len(r.m); n > r.maxEntries {
+			r.m.RemoveOldest(r.maxEntries-r.maxEntries/3, r.now())
+			r.meta.Evictions += uint64(n - len(r.m))
 		}
 		return
 	}
@@ -98,6 +116,7 @@ func (r *Repo) updateOrAdd(path string, t time.Time, subfolder bool) {
 		return
 	}
 	util.Logf("update timestamps: %q\n", path)
+	f.MissingSince = time.Time{}
 	if f.UpdateCount < math.MaxUint32 {
 		f.UpdateCount++
 	}
@@ -106,6 +125,68 @@ func (r *Repo) updateOrAdd(path string, t time.Time, subfolder bool) {
 	r.m[path] = f
 }
 
+// Remove the folder path from repo, returns false if unknown.
+func (r *Repo) Remove(path string) bool {
+	util.Logf("remove: %s\n", path)
+	return r.m.Remove(path)
+}
+
+// RemoveTree removes the folder root and all folders below it from
+// repo, returns the number of removed folders.
+func (r *Repo) RemoveTree(root string) int {
+	util.Logf("remove tree: %s\n", root)
+	return r.m.RemoveTree(root)
+}
+
+// Prune removes folders missing for longer than after and returns
+// their sorted paths, pinned folders are kept. Missing folders are
+// marked with the time first found missing, existing ones unmarked.
+// Only folders marked by an earlier prune are removed, so a folder
+// on an unmounted drive survives at least one run, whatever after is.
+// With dryRun the repo is unchanged, only the paths are returned.
+func (r *Repo) Prune(ch ResourceChecker, after time.Duration,
+	dryRun bool) []string {
+	now := r.now()
+	var a []string
+	for path, f := range r.m {
+		// pinned folders are kept, they are unpinned explicitly
+		if f.Pin != "" || ch.DoesExist(path) {
+			if !dryRun {
+				f.MissingSince = time.Time{}
+			}
+			continue
+		}
+		if f.MissingSince.IsZero() {
+			if !dryRun {
+				f.MissingSince = now
+			}
+			continue
+		}
+		if now.Sub(f.MissingSince) < after {
+			continue
+		}
+		a = append(a, path)
+		if !dryRun {
+			util.Logf("prune: %s\n", path)
+			delete(r.m, path)
+		}
+	}
+	if !dryRun {
+		r.meta.LastPrune = now
+	}
+	sort.Strings(a)
+	return a
+}
+
+// AutoPrune prunes the repo, if after is positive and the last
+// prune is older than AutoPruneInterval.
+func (r *Repo) AutoPrune(ch ResourceChecker, after time.Duration) []string {
+	if after <= 0 || r.now().Sub(r.meta.LastPrune) < AutoPruneInterval {
+		return nil
+	}
+	return r.Prune(ch, after, false)
+}
+
 // ResourceChecker returns true when a resource exists.
 type ResourceChecker interface {
 	DoesExist(string) bool
@@ -113,24 +194,59 @@ type ResourceChecker interface {
 
 // Search repo for query.
 func (r *Repo) Search(ch ResourceChecker, q pref.Query) (*rated.Rated, error) {
-	a := r.m.Search(q.Last, func(a rated.Slice) { a.Sort() })
+	a, err := r.Candidates(ch, q, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(a) == 0 {
+		return nil, ErrNoResult
+	}
+	return a[0], nil
+}
+
+// Candidates returns up to n best rated, existing results for query q.
+// If q is exactly the alias of an existing pinned folder, only the
+// pinned folder is returned. An error is returned if the ranker fails.
+func (r *Repo) Candidates(ch ResourceChecker, q pref.Query,
+	n int) (rated.Slice, error) {
+	if q.Start == "" {
+		if rf, ok := r.SearchPin(ch, q.Last); ok {
+			return rated.Slice{rf}, nil
+		}
+	}
+	a, err := r.m.Search(q.Last, r.rk, r.context(),
+		func(a rated.Slice) { a.Sort() })
+	if err != nil {
+		return nil, err
+	}
 	a.FilterInPathOf(q.Start)
+	var res rated.Slice
 	for _, v := range a {
+		if len(res) == n {
+			break
+		}
 		// keep not found folders, they might re-exist in future
 		if ch.DoesExist(v.Path) {
-			// if checkFolder(v.folder.Path) {
-			return v, nil
+			res = append(res, v)
 		}
 	}
-	return nil, ErrNoResult
+	return res, nil
 }
 
-// List returns all RatedSlice for the query q.
-func (r *Repo) List(q pref.Query, cutLong bool) rated.Slice {
-	a := r.m.Search(q.Last, func(a rated.Slice) { a.Sort() })
+// List returns all RatedSlice for the query q. An error is returned
+// if the ranker fails.
+func (r *Repo) List(q pref.Query) (rated.Slice, error) {
+	a, err := r.m.Search(q.Last, r.rk, r.context(),
+		func(a rated.Slice) { a.Sort() })
+	if err != nil {
+		return nil, err
+	}
 	a.FilterInPathOf(q.Start)
-	a.CutLongPaths(cutLong)
-	return a
+	return a, nil
+}
+
+func (r *Repo) context() classify.Context {
+	return classify.Context{Now: r.now()}
 }
 
 // Size of the repository.
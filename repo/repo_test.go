@@ -1,11 +1,15 @@
 package repo
 
 import (
+	"errors"
 	"math"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/thibran/maybe/classify"
 	"github.com/thibran/maybe/pref"
 	"github.com/thibran/maybe/rated"
 	"github.com/thibran/maybe/rated/folder"
@@ -183,7 +187,10 @@ func TestList(t *testing.T) {
 			for _, p := range paths {
 				r.updateOrAdd(p.p, p.t, false)
 			}
-			a := r.List(pref.Query{Last: tc.search}, false)
+			a, err := r.List(pref.Query{Last: tc.search})
+			if err != nil {
+				t.Fatal(err)
+			}
 			if len(a) != tc.resLen {
 				t.Fatalf("len(a) should be %v, got %v", tc.resLen, len(a))
 			}
@@ -193,3 +200,175 @@ func TestList(t *testing.T) {
 		})
 	}
 }
+
+func TestCandidates(t *testing.T) {
+	// pref.Verbose = true
+	now := time.Now()
+	r := New("/baz/bar/zot", 10)
+	r.updateOrAdd("/home/foo", now, false)
+	r.updateOrAdd("/tmp/foo", now, false)
+	r.updateOrAdd("/gone/foo", now, false)
+	doesExist := func(path string) bool {
+		return !strings.HasPrefix(path, "/gone")
+	}
+	a, err := r.Candidates(folder.ResourceCheckerFn(doesExist),
+		pref.Query{Last: "foo"}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 2 {
+		t.Fatalf("exp 2 candidates, got %d", len(a))
+	}
+	if !a.Ambiguous(1) {
+		t.Fatal("equally rated candidates should be ambiguous")
+	}
+	a, err = r.Candidates(folder.ResourceCheckerFn(doesExist),
+		pref.Query{Last: "foo"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 1 {
+		t.Fatalf("exp 1 candidate, got %d", len(a))
+	}
+}
+
+// failingRanker is a classify.BatchRanker which always fails.
+type failingRanker struct{}
+
+func (failingRanker) Rate(*folder.Folder, string,
+	classify.Context) (*classify.Rating, error) {
+	return nil, errors.New("ranker failed")
+}
+
+func (failingRanker) RateAll([]*folder.Folder, string,
+	classify.Context) ([]*classify.Rating, error) {
+	return nil, errors.New("ranker failed")
+}
+
+func TestCandidates_rankerFails(t *testing.T) {
+	r := New("/baz/bar/zot", 10)
+	r.updateOrAdd("/home/foo", time.Now(), false)
+	r.SetRanker(failingRanker{})
+	ch := folder.ResourceCheckerFn(func(string) bool { return true })
+	q := pref.Query{Last: "foo"}
+	if _, err := r.Candidates(ch, q, 5); err == nil {
+		t.Error("Candidates: exp ranker error")
+	}
+	if _, err := r.Search(ch, q); err == nil || err == ErrNoResult {
+		t.Errorf("Search: exp ranker error, got %v", err)
+	}
+	if _, err := r.List(q); err == nil {
+		t.Error("List: exp ranker error")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	r := New("/baz/bar/zot", 10)
+	r.Add("/home/foo/bar", time.Now())
+	if !r.Remove("/home/foo") {
+		t.Fatal("exp /home/foo removed")
+	}
+	if r.Remove("/home/foo") {
+		t.Fatal("removed unknown folder")
+	}
+	if n := r.RemoveTree("/home"); n != 2 || r.Size() != 0 {
+		t.Fatalf("exp 2 removed and empty repo, got %d and %d", n, r.Size())
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	r := New("/baz/bar/zot", 10)
+	r.SetClock(func() time.Time { return now })
+	r.updateOrAdd("/home/foo", now, false)
+	r.updateOrAdd("/gone/new", now, false)
+	r.updateOrAdd("/gone/old", now, false)
+	r.m["/gone/old"].MissingSince = now.Add(-48 * time.Hour)
+	doesExist := folder.ResourceCheckerFn(func(path string) bool {
+		return !strings.HasPrefix(path, "/gone")
+	})
+	if a := r.Prune(doesExist, 24*time.Hour, true); len(a) != 1 ||
+		r.Size() != 3 || !r.m["/gone/new"].MissingSince.IsZero() {
+		t.Fatalf("dry run changed repo or returned %v", a)
+	}
+	a := r.Prune(doesExist, 24*time.Hour, false)
+	if len(a) != 1 || a[0] != "/gone/old" {
+		t.Fatalf("exp [/gone/old], got %v", a)
+	}
+	if !r.m["/gone/new"].MissingSince.Equal(now) {
+		t.Fatal("/gone/new should be marked missing")
+	}
+	if r.AutoPrune(doesExist, time.Nanosecond) != nil {
+		t.Fatal("auto prune should wait for AutoPruneInterval")
+	}
+	r.updateOrAdd("/gone/new", now, false)
+	if !r.m["/gone/new"].MissingSince.IsZero() {
+		t.Fatal("visit should unmark /gone/new")
+	}
+	if a := r.Prune(doesExist, 0, false); len(a) != 0 || r.Size() != 2 {
+		t.Fatalf("exp freshly missing /gone/new kept, got %v", a)
+	}
+	if a := r.Prune(doesExist, 0, false); len(a) != 1 || r.Size() != 1 {
+		t.Fatalf("exp all missing pruned, got %v", a)
+	}
+}
+
+func TestPrune_freshlyMissing(t *testing.T) {
+	now := time.Now()
+	r := New("/baz/bar/zot", 10)
+	r.SetClock(func() time.Time { return now })
+	r.updateOrAdd("/mnt/usb/photos", now, false)
+	unmounted := folder.ResourceCheckerFn(func(string) bool { return false })
+	for _, dryRun := range []bool{true, false} {
+		if a := r.Prune(unmounted, 0, dryRun); len(a) != 0 {
+			t.Fatalf("dry run %v: exp freshly missing folder kept, got %v",
+				dryRun, a)
+		}
+	}
+	now = now.Add(time.Hour)
+	if a := r.Prune(unmounted, pref.DefaultPruneAfter, false); len(a) != 0 {
+		t.Fatalf("exp folder kept for the grace period, got %v", a)
+	}
+	now = now.Add(pref.DefaultPruneAfter)
+	if a := r.Prune(unmounted, pref.DefaultPruneAfter, false); len(a) != 1 {
+		t.Fatalf("exp folder pruned after the grace period, got %v", a)
+	}
+}
+
+func TestPrune_notDir(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "afile")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := New("/baz/bar/zot", 10)
+	r.updateOrAdd(filepath.Join(file, "sub"), time.Now(), false)
+	r.Prune(folder.CheckerFn(), 0, false)
+	if a := r.Prune(folder.CheckerFn(), 0, false); len(a) != 1 {
+		t.Fatalf("exp the path below a file pruned, got %v", a)
+	}
+}
+
+func TestCandidates_baseNameFirst(t *testing.T) {
+	now := time.Now()
+	r := New("/baz/bar/zot", 100)
+	r.SetClock(func() time.Time { return now })
+	r.Add("/home/src/maybe", now.AddDate(0, -7, 0))
+	for i := 0; i < 6; i++ {
+		r.Add("/home/src/maybe/classify", now.Add(-time.Duration(i)*time.Minute))
+	}
+	a, err := r.Candidates(folder.ResourceCheckerFn(func(string) bool { return true }),
+		pref.Query{Last: "maybe"}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 2 || a[0].Path != "/home/src/maybe" {
+		t.Fatalf("exp /home/src/maybe first, got %v", a)
+	}
+	if a[1].Points() <= a[0].Points() {
+		t.Fatalf("the child should have more points, got %d and %d",
+			a[1].Points(), a[0].Points())
+	}
+	if a.Ambiguous(1000) {
+		t.Fatal("a parent segment match should not be ambiguous")
+	}
+}
@@ -19,7 +19,10 @@ func (r *Repo) Save() error {
 		log.Fatalf("could not save filerepo: %s %v\n", r.dataDir, err)
 	}
 	defer f.Close()
-	return saveGzip(f, r.m)
+	if err := saveGzip(f, r.m); err != nil {
+		return err
+	}
+	return r.saveMeta()
 }
 
 func saveGzip(w io.Writer, data rated.Map) error {
@@ -60,7 +63,7 @@ func (r *Repo) loadFile() error {
 		return err
 	}
 	r.m = m
-	return nil
+	return r.loadMeta()
 }
 
 func loadGzip(r io.Reader) (rated.Map, error) {
@@ -19,6 +19,7 @@ func TestLoadFile(t *testing.T) {
 		log.Fatal(err)
 	}
 	defer os.Remove(tmp.Name())
+	defer os.Remove(tmp.Name() + ".meta")
 	r := New(tmp.Name(), 10)
 	r.Save()
 	if err := r.loadFile(); err != nil {
@@ -51,6 +52,7 @@ func TestSave(t *testing.T) {
 		log.Fatal(err)
 	}
 	defer os.Remove(tmp.Name())
+	defer os.Remove(tmp.Name() + ".meta")
 	r := New(tmp.Name(), 10)
 	if err := r.Save(); err != nil {
 		t.Fatal(err)
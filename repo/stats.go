@@ -0,0 +1,140 @@
+package repo
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"github.com/thibran/maybe/rated/folder"
+)
+
+// SeedTime is the visit time of folders added by Walk, which
+// were never visited.
+var SeedTime = time.Date(2000, time.January, 0, 0, 0, 0, 0, time.UTC)
+
+// Histogram sizes of Stats.
+const (
+	StatsDays  = 14
+	StatsWeeks = 8
+)
+
+// Stats of the repo.
+type Stats struct {
+	Entries     int       `json:"entries"`
+	MaxEntries  int       `json:"max_entries"`
+	FileSize    int64     `json:"file_size"`
+	Missing     int       `json:"missing"`
+	InitOnly    int       `json:"init_only"`
+	Evictions   uint64    `json:"evictions"`
+	LastPrune   time.Time `json:"last_prune"`
+	MostVisited []Visited `json:"most_visited"`
+	Recent      []Visited `json:"recent"`
+	// PerDay and PerWeek count the visits, oldest bucket first.
+	// Only the last rated.MaxTimeEntries visits of a folder are known.
+	PerDay  []Bucket `json:"per_day"`
+	PerWeek []Bucket `json:"per_week"`
+}
+
+// Visited folder of Stats.
+type Visited struct {
+	Path      string    `json:"path"`
+	Visits    uint32    `json:"visits"`
+	LastVisit time.Time `json:"last_visit"`
+}
+
+// Bucket of a visit histogram, the visits from Start on.
+type Bucket struct {
+	Start  time.Time `json:"start"`
+	Visits int       `json:"visits"`
+}
+
+// Stats of the repo, with up to n most visited and recent folders.
+func (r *Repo) Stats(ch ResourceChecker, n int) Stats {
+	s := Stats{
+		Entries:    len(r.m),
+		MaxEntries: r.maxEntries,
+		Evictions:  r.meta.Evictions,
+		LastPrune:  r.meta.LastPrune,
+	}
+	if fi, err := os.Stat(r.dataDir); err == nil {
+		s.FileSize = fi.Size()
+	}
+	now := r.now()
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	s.PerDay = buckets(today, StatsDays, 1)
+	s.PerWeek = buckets(today, StatsWeeks, 7)
+	var visited []Visited
+	// Add gives the parent folders the time of the visit, so
+	// every visit time is counted once
+	seen := make(map[int64]bool)
+	for path, f := range r.m {
+		if !ch.DoesExist(path) {
+			s.Missing++
+		}
+		if isInitOnly(f) {
+			s.InitOnly++
+			continue
+		}
+		v := Visited{Path: path, Visits: f.UpdateCount}
+		for _, t := range f.Times {
+			if t.After(v.LastVisit) {
+				v.LastVisit = t
+			}
+			if seen[t.UnixNano()] {
+				continue
+			}
+			seen[t.UnixNano()] = true
+			count(s.PerDay, t, 1)
+			count(s.PerWeek, t, 7)
+		}
+		visited = append(visited, v)
+	}
+	sort.Slice(visited, func(i, j int) bool {
+		if visited[i].Visits == visited[j].Visits {
+			return visited[i].Path < visited[j].Path
+		}
+		return visited[i].Visits > visited[j].Visits
+	})
+	s.MostVisited = top(visited, n)
+	sort.SliceStable(visited, func(i, j int) bool {
+		return visited[i].LastVisit.After(visited[j].LastVisit)
+	})
+	s.Recent = top(visited, n)
+	return s
+}
+
+// isInitOnly returns true if f was added by Walk and never visited.
+func isInitOnly(f *folder.Folder) bool {
+	return f.UpdateCount <= 1 && len(f.Times) == 1 &&
+		f.Times[0].Equal(SeedTime)
+}
+
+// buckets returns n buckets of days length, the last ends today.
+func buckets(today time.Time, n, days int) []Bucket {
+	a := make([]Bucket, n)
+	for i := range a {
+		a[i].Start = today.AddDate(0, 0, 1-days*(n-i))
+	}
+	return a
+}
+
+// count t in the bucket of days length it falls in.
+func count(a []Bucket, t time.Time, days int) {
+	for i := len(a) - 1; i >= 0; i-- {
+		if t.Before(a[i].Start) {
+			continue
+		}
+		if t.Before(a[i].Start.AddDate(0, 0, days)) {
+			a[i].Visits++
+		}
+		return
+	}
+}
+
+func top(a []Visited, n int) []Visited {
+	if n > 0 && len(a) > n {
+		a = a[:n]
+	}
+	return append([]Visited{}, a...)
+}
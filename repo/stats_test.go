@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thibran/maybe/rated/folder"
+)
+
+func TestStats(t *testing.T) {
+	now := time.Date(2020, time.May, 20, 12, 0, 0, 0, time.UTC)
+	r := New("/baz/bar/zot", 10)
+	r.SetClock(func() time.Time { return now })
+	r.Add("/home/foo", now.Add(-time.Hour))
+	r.Add("/home/foo", now.AddDate(0, 0, -1))
+	r.Add("/home/foo", now.AddDate(0, 0, -10))
+	r.Add("/gone/bar", now.AddDate(0, 0, -2))
+	r.updateOrAdd("/home/seed", SeedTime, true)
+	r.meta.Evictions = 3
+	doesExist := folder.ResourceCheckerFn(func(path string) bool {
+		return !strings.HasPrefix(path, "/gone")
+	})
+	s := r.Stats(doesExist, 2)
+	if s.Entries != 5 || s.MaxEntries != 10 || s.Evictions != 3 {
+		t.Fatalf("exp 5 of 10 entries and 3 evictions, got %+v", s)
+	}
+	if s.Missing != 2 || s.InitOnly != 1 {
+		t.Errorf("exp 2 missing and 1 init only, got %d and %d",
+			s.Missing, s.InitOnly)
+	}
+	if len(s.MostVisited) != 2 || s.MostVisited[0].Path != "/home/foo" ||
+		s.MostVisited[0].Visits != 3 {
+		t.Errorf("exp /home/foo most visited, got %+v", s.MostVisited)
+	}
+	if len(s.Recent) != 2 || !s.Recent[0].LastVisit.Equal(now.Add(-time.Hour)) {
+		t.Errorf("exp last visit an hour ago, got %+v", s.Recent)
+	}
+	if len(s.PerDay) != StatsDays || len(s.PerWeek) != StatsWeeks {
+		t.Fatalf("exp %d days and %d weeks, got %d and %d", StatsDays,
+			StatsWeeks, len(s.PerDay), len(s.PerWeek))
+	}
+	// the parents of a visit are not counted
+	days := []int{s.PerDay[StatsDays-1].Visits, s.PerDay[StatsDays-2].Visits,
+		s.PerDay[StatsDays-3].Visits, s.PerDay[StatsDays-11].Visits}
+	if fmt.Sprint(days) != "[1 1 1 1]" {
+		t.Errorf("exp one visit on each day, got %v", days)
+	}
+	if s.PerWeek[StatsWeeks-1].Visits != 3 || s.PerWeek[StatsWeeks-2].Visits != 1 {
+		t.Errorf("exp 3 and 1 visits in the last weeks, got %+v", s.PerWeek)
+	}
+}
+
+func TestEvictions(t *testing.T) {
+	now := time.Now()
+	r := New("/baz/bar/zot", 3)
+	for _, p := range []string{"/a", "/b", "/c", "/d"} {
+		r.updateOrAdd(p, now, false)
+	}
+	if r.meta.Evictions != 2 || r.Size() != 2 {
+		t.Fatalf("exp 2 evictions and entries, got %d and %d",
+			r.meta.Evictions, r.Size())
+	}
+}
@@ -33,7 +33,7 @@ func newWalker(r *Repo, root string) *walker {
 		root:    fp.Clean(root), // converts e.g. /foo/ to /foo
 		lvlDeep: lvlDeep,
 		r:       r,
-		now:     time.Date(2000, time.January, 0, 0, 0, 0, 0, time.UTC),
+		now:     SeedTime,
 		count:   len(r.m),
 	}
 }
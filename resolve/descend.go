@@ -0,0 +1,97 @@
+package resolve
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thibran/maybe/classify"
+	"github.com/thibran/maybe/pref"
+)
+
+const sep = string(os.PathSeparator)
+
+// SubPath splits a relative sub-path off query q. With a start, the
+// keyword is the start and the sub-path the last word, e.g. proj
+// src/cmd, else the first segment of the last word is the keyword,
+// e.g. proj/src/cmd. Returns q unchanged and an empty sub-path, if
+// the last word contains no sub-path.
+func SubPath(q pref.Query) (pref.Query, string) {
+	last := q.Last
+	if !strings.Contains(last, sep) || filepath.IsAbs(last) ||
+		strings.HasPrefix(last, "~") || strings.HasPrefix(last, "$") ||
+		strings.HasPrefix(last, ".") {
+		return q, ""
+	}
+	if q.Start != "" {
+		return pref.Query{Last: q.Start}, strings.Trim(last, sep)
+	}
+	i := strings.Index(last, sep)
+	return pref.Query{Last: last[:i]}, strings.Trim(last[i:], sep)
+}
+
+// Descend from directory dir into the relative sub-path sub. Every
+// segment of sub is an existing directory, or else matched against
+// the names of the directories at its level, the best similar one
+// wins. Returns the path and true, if all segments were found.
+func Descend(dir, sub string) (string, bool) {
+	for _, seg := range strings.Split(sub, sep) {
+		switch seg {
+		case "", ".":
+			continue
+		case "..":
+			dir = filepath.Dir(dir)
+			continue
+		}
+		next, ok := existing(filepath.Join(dir, seg))
+		if !ok {
+			if next, ok = similarDir(dir, seg); !ok {
+				return "", false
+			}
+		}
+		dir = next
+	}
+	return dir, true
+}
+
+// similarDir returns the sub-directory of dir most similar to name.
+// Of equally rated names the shorter, then the alphabetically first
+// wins.
+func similarDir(dir, name string) (string, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	var best string
+	var bestPoints uint
+	for _, e := range entries {
+		if !isDir(dir, e) {
+			continue
+		}
+		n := classify.Text(e.Name(), name)
+		if n == classify.NoMatch || n < bestPoints {
+			continue
+		}
+		// entries are sorted by name
+		if n == bestPoints && len(e.Name()) >= len(best) {
+			continue
+		}
+		best, bestPoints = e.Name(), n
+	}
+	if best == "" {
+		return "", false
+	}
+	return filepath.Join(dir, best), true
+}
+
+// isDir returns true if e is a directory, or a symlink to one.
+func isDir(dir string, e os.DirEntry) bool {
+	if e.IsDir() {
+		return true
+	}
+	if e.Type()&os.ModeSymlink == 0 {
+		return false
+	}
+	_, ok := existing(filepath.Join(dir, e.Name()))
+	return ok
+}
@@ -0,0 +1,78 @@
+package resolve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thibran/maybe/pref"
+)
+
+func TestSubPath(t *testing.T) {
+	tt := []struct {
+		name   string
+		q, exp pref.Query
+		expSub string
+	}{
+		{name: "keyword", q: pref.Query{Last: "proj"},
+			exp: pref.Query{Last: "proj"}},
+		{name: "start", q: pref.Query{Start: "proj", Last: "src/cmd"},
+			exp: pref.Query{Last: "proj"}, expSub: "src/cmd"},
+		{name: "one word", q: pref.Query{Last: "proj/src/cmd/"},
+			exp: pref.Query{Last: "proj"}, expSub: "src/cmd"},
+		{name: "start filter", q: pref.Query{Start: "src", Last: "cmd"},
+			exp: pref.Query{Start: "src", Last: "cmd"}},
+		{name: "absolute", q: pref.Query{Last: "/src/cmd"},
+			exp: pref.Query{Last: "/src/cmd"}},
+		{name: "relative", q: pref.Query{Last: "../cmd"},
+			exp: pref.Query{Last: "../cmd"}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			q, sub := SubPath(tc.q)
+			if q != tc.exp || sub != tc.expSub {
+				t.Errorf("exp %v %q, got %v %q", tc.exp, tc.expSub, q, sub)
+			}
+		})
+	}
+}
+
+func TestDescend(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"src/cmd/maybe", "src/command", "src/pkg",
+		"docs", ".git"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "srcfile"), nil,
+		0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "docs"),
+		filepath.Join(root, "manual")); err != nil {
+		t.Fatal(err)
+	}
+	j := func(s string) string { return filepath.Join(root, s) }
+	tt := []struct {
+		name, sub, exp string
+		ok             bool
+	}{
+		{name: "exact", sub: "src/cmd", exp: j("src/cmd"), ok: true},
+		{name: "shortest of equal", sub: "sr/cm", exp: j("src/cmd"), ok: true},
+		{name: "similar", sub: "src/pkq/", exp: j("src/pkg"), ok: true},
+		{name: "deep", sub: "s/c/may", exp: j("src/cmd/maybe"), ok: true},
+		{name: "dot dot", sub: "src/../docs", exp: j("docs"), ok: true},
+		{name: "symlink", sub: "manu", exp: j("manual"), ok: true},
+		{name: "hidden", sub: ".gi", exp: j(".git"), ok: true},
+		{name: "no match", sub: "src/xyz"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			res, ok := Descend(root, tc.sub)
+			if res != tc.exp || ok != tc.ok {
+				t.Errorf("exp %q %t, got %q %t", tc.exp, tc.ok, res, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Package resolve resolves cd-style arguments to directories.
+package resolve
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// Env of a resolution, the state of the calling shell.
+type Env struct {
+	PWD, OldPWD, CDPath, Home string
+	Getenv                    func(key string) string
+	// LookupHome returns the home directory of user name
+	LookupHome func(name string) (string, error)
+}
+
+// OSEnv returns the environment of the process.
+func OSEnv() Env {
+	pwd, err := os.Getwd()
+	if err != nil {
+		pwd = os.Getenv("PWD")
+	}
+	home, _ := os.UserHomeDir()
+	return Env{
+		PWD:    pwd,
+		OldPWD: os.Getenv("OLDPWD"),
+		CDPath: os.Getenv("CDPATH"),
+		Home:   home,
+		Getenv: os.Getenv,
+		LookupHome: func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.HomeDir, nil
+		},
+	}
+}
+
+// Dir resolves arg like cd does and returns the clean, absolute
+// directory and true if it exists. Resolved are - (the previous
+// directory), environment variables, ~ and ~user, absolute paths
+// and paths relative to the working directory, or else to an
+// entry of CDPATH. Paths starting with . or .. are never looked up
+// in CDPATH.
+func Dir(arg string, e Env) (string, bool) {
+	if arg == "" {
+		return "", false
+	}
+	if arg == "-" {
+		return existing(e.OldPWD)
+	}
+	if strings.Contains(arg, "$") && e.Getenv != nil {
+		if arg = os.Expand(arg, e.Getenv); arg == "" {
+			return "", false
+		}
+	}
+	if p, ok := tilde(arg, e); ok {
+		arg = p
+	}
+	if filepath.IsAbs(arg) {
+		return existing(arg)
+	}
+	if e.PWD == "" {
+		return "", false
+	}
+	if p, ok := existing(filepath.Join(e.PWD, arg)); ok {
+		return p, true
+	}
+	if arg == "." || arg == ".." || strings.HasPrefix(arg, "./") ||
+		strings.HasPrefix(arg, "../") {
+		return "", false
+	}
+	for _, dir := range filepath.SplitList(e.CDPath) {
+		if dir == "" {
+			continue // the working directory, checked above
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(e.PWD, dir)
+		}
+		if p, ok := existing(filepath.Join(dir, arg)); ok {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// tilde expands a leading ~ or ~user of p.
+func tilde(p string, e Env) (string, bool) {
+	if !strings.HasPrefix(p, "~") {
+		return "", false
+	}
+	name, rest := p[1:], ""
+	if i := strings.IndexRune(name, '/'); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+	home := e.Home
+	if name != "" {
+		if e.LookupHome == nil {
+			return "", false
+		}
+		h, err := e.LookupHome(name)
+		if err != nil {
+			return "", false
+		}
+		home = h
+	}
+	if home == "" {
+		return "", false
+	}
+	return home + rest, true
+}
+
+// existing returns the clean path p and true, if p is a directory.
+func existing(p string) (string, bool) {
+	if p == "" || !filepath.IsAbs(p) {
+		return "", false
+	}
+	p = filepath.Clean(p)
+	fi, err := os.Stat(p)
+	if err != nil || !fi.IsDir() {
+		return "", false
+	}
+	return p, true
+}
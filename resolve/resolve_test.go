@@ -0,0 +1,74 @@
+package resolve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDir(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"home/tux/src", "home/zot", "work/cmd",
+		"cdpath/proj", "cdpath/cmd"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "work", "file"), nil,
+		0644); err != nil {
+		t.Fatal(err)
+	}
+	j := func(s string) string { return filepath.Join(root, s) }
+	env := map[string]string{"SRC": j("home/tux/src"), "EMPTY": ""}
+	e := Env{
+		PWD:    j("work"),
+		OldPWD: j("home/zot"),
+		CDPath: ":" + j("cdpath"),
+		Home:   j("home/tux"),
+		Getenv: func(k string) string { return env[k] },
+		LookupHome: func(name string) (string, error) {
+			if name == "zot" {
+				return j("home/zot"), nil
+			}
+			return "", fmt.Errorf("unknown user %s", name)
+		},
+	}
+	tt := []struct {
+		name, arg, exp string
+		ok             bool
+	}{
+		{name: "empty", arg: ""},
+		{name: "previous", arg: "-", exp: j("home/zot"), ok: true},
+		{name: "home", arg: "~", exp: j("home/tux"), ok: true},
+		{name: "home sub", arg: "~/src", exp: j("home/tux/src"), ok: true},
+		{name: "user", arg: "~zot", exp: j("home/zot"), ok: true},
+		{name: "unknown user", arg: "~nobody"},
+		{name: "env", arg: "$SRC", exp: j("home/tux/src"), ok: true},
+		{name: "env braces", arg: "${SRC}/..", exp: j("home/tux"), ok: true},
+		{name: "empty env", arg: "$EMPTY"},
+		{name: "dot", arg: ".", exp: j("work"), ok: true},
+		{name: "dot dot", arg: "..", exp: root, ok: true},
+		{name: "relative", arg: "cmd", exp: j("work/cmd"), ok: true},
+		{name: "cdpath", arg: "proj", exp: j("cdpath/proj"), ok: true},
+		{name: "no cdpath for dot", arg: "./proj"},
+		{name: "absolute", arg: j("home"), exp: j("home"), ok: true},
+		{name: "missing absolute", arg: j("nope")},
+		{name: "file", arg: "file"},
+		{name: "keyword", arg: "maybe"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			res, ok := Dir(tc.arg, e)
+			if res != tc.exp || ok != tc.ok {
+				t.Errorf("exp %q %t, got %q %t", tc.exp, tc.ok, res, ok)
+			}
+		})
+	}
+}
+
+func TestDirNoOldPWD(t *testing.T) {
+	if res, ok := Dir("-", Env{PWD: "/"}); ok {
+		t.Errorf("unexpected %q", res)
+	}
+}
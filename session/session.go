@@ -0,0 +1,92 @@
+// Package session persists the results listed last, per terminal
+// session, so they can be selected by number.
+package session
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	filePrefix = "last-list."
+	maxAge     = time.Hour * 24 * 7 // lists of older sessions are removed
+)
+
+var (
+	// ErrNoList - no list was saved for the session
+	ErrNoList = errors.New("no saved list")
+	// ErrOutOfRange - the selected number is not part of the list
+	ErrOutOfRange = errors.New("number out of range")
+)
+
+// ID of the current terminal session, $MAYBE_SESSION if set,
+// else the parent process id, usually the shell.
+func ID() string {
+	if id := strings.TrimSpace(os.Getenv("MAYBE_SESSION")); id != "" {
+		return id
+	}
+	return strconv.Itoa(os.Getppid())
+}
+
+func path(dir, id string) string {
+	// keep the id a single file name
+	id = strings.Replace(id, string(os.PathSeparator), "_", -1)
+	return filepath.Join(dir, filePrefix+id)
+}
+
+// Save paths for session id in dir. Lists of sessions
+// not touched for a week are removed.
+func Save(dir, id string, paths []string) error {
+	removeOld(dir)
+	var buf bytes.Buffer
+	for _, p := range paths {
+		buf.WriteString(p)
+		buf.WriteByte(0)
+	}
+	return ioutil.WriteFile(path(dir, id), buf.Bytes(), 0660)
+}
+
+// Load paths of session id from dir.
+func Load(dir, id string) ([]string, error) {
+	buf, err := ioutil.ReadFile(path(dir, id))
+	if os.IsNotExist(err) {
+		return nil, ErrNoList
+	}
+	if err != nil {
+		return nil, err
+	}
+	a := strings.Split(string(buf), "\x00")
+	return a[:len(a)-1], nil
+}
+
+// Select the n-th path, starting with 1, of session id from dir.
+func Select(dir, id string, n int) (string, error) {
+	a, err := Load(dir, id)
+	if err != nil {
+		return "", err
+	}
+	if n < 1 || n > len(a) {
+		return "", fmt.Errorf("%v: %d of %d", ErrOutOfRange, n, len(a))
+	}
+	return a[n-1], nil
+}
+
+func removeOld(dir string) {
+	a, err := filepath.Glob(filepath.Join(dir, filePrefix+"*"))
+	if err != nil {
+		return
+	}
+	for _, p := range a {
+		if fi, err := os.Stat(p); err == nil &&
+			time.Since(fi.ModTime()) > maxAge {
+			os.Remove(p)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package session
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveSelect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "maybe.session_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if _, err := Select(dir, "1", 1); err != ErrNoList {
+		t.Fatalf("exp ErrNoList, got %v", err)
+	}
+	if err := Save(dir, "1", []string{"/home/foo", "/tmp/new\nline"}); err != nil {
+		t.Fatal(err)
+	}
+	tt := []struct {
+		name, exp string
+		n         int
+		err       bool
+	}{
+		{name: "first", n: 1, exp: "/home/foo"},
+		{name: "second", n: 2, exp: "/tmp/new\nline"},
+		{name: "zero", n: 0, err: true},
+		{name: "too large", n: 3, err: true},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := Select(dir, "1", tc.n)
+			if tc.err != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p != tc.exp {
+				t.Fatalf("exp %q, got %q", tc.exp, p)
+			}
+		})
+	}
+	if _, err := Select(dir, "2", 1); err != ErrNoList {
+		t.Fatalf("other session should have no list, got %v", err)
+	}
+}
+
+func TestSave_removeOld(t *testing.T) {
+	dir, err := ioutil.TempDir("", "maybe.session_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := Save(dir, "old", nil); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-maxAge - time.Hour)
+	os.Chtimes(filepath.Join(dir, filePrefix+"old"), old, old)
+	if err := Save(dir, "new", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, "old"); err != ErrNoList {
+		t.Fatalf("old list should be removed, got %v", err)
+	}
+	if _, err := Load(dir, "new"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,94 @@
+package shell
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thibran/maybe/classify"
+	"github.com/thibran/maybe/pref"
+	"github.com/thibran/maybe/rated"
+	"github.com/thibran/maybe/util"
+)
+
+// Candidate of a completion, printed as value<tab>description.
+type Candidate struct {
+	Value, Description string
+}
+
+// Argument kinds of a Flag, used by the completion scripts.
+const (
+	ArgNone   = ""       // bool flag
+	ArgAny    = "any"    // no completion
+	ArgDir    = "dir"    // a directory
+	ArgFile   = "file"   // a file
+	ArgFolder = "folder" // a keyword, completed by -complete
+	ArgValues = "values" // one of Flag.Values
+)
+
+// Flag of maybe, completed by the completion scripts.
+type Flag struct {
+	Name, Usage string
+	Arg         string
+	Values      []string
+}
+
+// Query to complete partial, the last keyword. If partial contains a
+// path separator, its base name is searched. The start keyword is optional.
+func Query(start, partial string) pref.Query {
+	if strings.Contains(partial, string(os.PathSeparator)) {
+		partial = filepath.Base(partial)
+	}
+	return pref.Query{Start: start, Last: partial}
+}
+
+// Candidates for partial of the rated folders a. The values are the
+// unique base names matching partial, or the full paths with prefix
+// partial, if partial contains a path separator. A leading ~ of
+// partial is expanded. Folders matched only by a parent segment are
+// skipped. An empty partial after start completes the folders below
+// those matching start. Descriptions contain points and path.
+func Candidates(a rated.Slice, start, partial string) []Candidate {
+	byPath := strings.Contains(partial, string(os.PathSeparator))
+	children := partial == "" && start != ""
+	prefix := util.ExpandTilde(partial)
+	seen := make(map[string]bool, len(a))
+	var res []Candidate
+	for _, rf := range a {
+		v := filepath.Base(rf.Path)
+		switch {
+		case byPath:
+			if !strings.HasPrefix(rf.Path, prefix) {
+				continue
+			}
+			v = rf.Path
+		case children:
+			parent := filepath.Base(filepath.Dir(rf.Path))
+			if classify.Text(parent, start) == classify.NoMatch {
+				continue
+			}
+		case classify.Text(v, partial) == classify.NoMatch:
+			continue
+		}
+		// would break the line based output
+		if seen[v] || strings.ContainsAny(v, "\t\n") {
+			continue
+		}
+		seen[v] = true
+		res = append(res, Candidate{Value: v,
+			Description: fmt.Sprintf("%d %s", rf.Points(), util.Tilde(rf.Path))})
+	}
+	return res
+}
+
+// WriteCandidates to w, one value<tab>description line each.
+func WriteCandidates(w io.Writer, a []Candidate) error {
+	for _, c := range a {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", c.Value, c.Description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
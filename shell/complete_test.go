@@ -0,0 +1,97 @@
+package shell
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/thibran/maybe/classify"
+	"github.com/thibran/maybe/pref"
+	"github.com/thibran/maybe/rated"
+	"github.com/thibran/maybe/rated/folder"
+)
+
+func TestQuery(t *testing.T) {
+	tt := []struct {
+		start, partial string
+		exp            pref.Query
+	}{
+		{partial: "foo", exp: pref.Query{Last: "foo"}},
+		{start: "src", partial: "foo", exp: pref.Query{Start: "src", Last: "foo"}},
+		{partial: "/home/tux/fo", exp: pref.Query{Last: "fo"}},
+	}
+	for _, tc := range tt {
+		if res := Query(tc.start, tc.partial); res != tc.exp {
+			t.Errorf("exp %v, got %v", tc.exp, res)
+		}
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	home := os.Getenv("HOME")
+	defer os.Setenv("HOME", home)
+	rf := func(path string, points uint) *rated.Rated {
+		return &rated.Rated{Folder: &folder.Folder{Path: path},
+			Rating: &classify.Rating{SimilarityPoints: points}}
+	}
+	a := rated.Slice{
+		rf("/home/tux/foo", 9),
+		rf("/srv/foo", 7),
+		rf("/home/tux/food", 5),
+		rf("/home/tux/a\tb", 3),
+		rf("/home/tux/foo/classify", 2),
+	}
+	tt := []struct {
+		name, start, partial string
+		home                 string // default /nonexistent
+		exp                  []Candidate
+	}{
+		{name: "base names", partial: "fo", exp: []Candidate{
+			{Value: "foo", Description: "9 /home/tux/foo"},
+			{Value: "food", Description: "5 /home/tux/food"}}},
+		{name: "no parent matches", partial: "foo", exp: []Candidate{
+			{Value: "foo", Description: "9 /home/tux/foo"},
+			{Value: "food", Description: "5 /home/tux/food"}}},
+		{name: "paths", partial: "/home/tux/fo", exp: []Candidate{
+			{Value: "/home/tux/foo", Description: "9 /home/tux/foo"},
+			{Value: "/home/tux/food", Description: "5 /home/tux/food"},
+			{Value: "/home/tux/foo/classify",
+				Description: "2 /home/tux/foo/classify"}}},
+		{name: "tilde", partial: "~/fo", home: "/home/tux", exp: []Candidate{
+			{Value: "/home/tux/foo", Description: "9 ~/foo"},
+			{Value: "/home/tux/food", Description: "5 ~/food"},
+			{Value: "/home/tux/foo/classify", Description: "2 ~/foo/classify"}}},
+		{name: "children", start: "tux", exp: []Candidate{
+			{Value: "foo", Description: "9 /home/tux/foo"},
+			{Value: "food", Description: "5 /home/tux/food"}}},
+		{name: "children of foo", start: "foo", exp: []Candidate{
+			{Value: "classify", Description: "2 /home/tux/foo/classify"}}},
+		{name: "start and partial", start: "tux", partial: "foo", exp: []Candidate{
+			{Value: "foo", Description: "9 /home/tux/foo"},
+			{Value: "food", Description: "5 /home/tux/food"}}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Setenv("HOME", "/nonexistent")
+			if tc.home != "" {
+				os.Setenv("HOME", tc.home)
+			}
+			res := Candidates(a, tc.start, tc.partial)
+			if !reflect.DeepEqual(res, tc.exp) {
+				t.Errorf("exp %v, got %v", tc.exp, res)
+			}
+		})
+	}
+}
+
+func TestWriteCandidates(t *testing.T) {
+	var b bytes.Buffer
+	err := WriteCandidates(&b, []Candidate{{Value: "foo", Description: "9 /foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "foo\t9 /foo\n"; b.String() != exp {
+		t.Errorf("exp %q, got %q", exp, b.String())
+	}
+}
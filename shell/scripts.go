@@ -0,0 +1,344 @@
+package shell
+
+// scripts are text/templates executed with Options. The jump function
+// changes to $HOME without arguments, the list function passes its
+// arguments to maybe list. MAYBE_SESSION pins the session of -select and
+// bare numbers to the shell, not to the subshell running maybe.
+var scripts = map[string]string{
+	"bash":    bash,
+	"zsh":     zsh,
+	"fish":    fish,
+	"nushell": nushell,
+	"elvish":  elvish,
+}
+
+const bash = `# maybe shell integration for bash
+# eval "$(maybe init bash)"
+
+export MAYBE_SESSION=$$
+
+\builtin unalias {{.Cmd}} {{.ListCmd}} &>/dev/null || \builtin true
+
+{{.Cmd}}() {
+    if [[ $# -eq 0 ]]; then
+        builtin cd -- ~
+        return
+    fi
+    local d
+    # OLDPWD resolves -, it is not always exported
+    d=$(OLDPWD=$OLDPWD command maybe search -- "$@")
+    case $? in
+    0) ;;
+    # ambiguous or too weak, let the user pick
+    3) d=$(command maybe pick -- "$@") || return 3 ;;
+    *) return 2 ;;
+    esac
+    if [[ $d != "$PWD" ]]; then
+        builtin cd -- "$d"
+    fi
+}
+
+{{.ListCmd}}() {
+    command maybe list "$@"
+}
+{{- if .Hook}}
+
+__maybe_pwd=$PWD
+__maybe_hook() {
+    if [[ $__maybe_pwd != "$PWD" ]]; then
+        __maybe_pwd=$PWD
+        command maybe add -- "$PWD"
+    fi
+}
+
+if [[ ${PROMPT_COMMAND[*]} != *__maybe_hook* ]]; then
+    PROMPT_COMMAND="__maybe_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+{{- end}}
+{{- if .Completion}}
+
+# complete keywords by: maybe complete <partial> [start]
+__maybe_folders() {
+    local c
+    COMPREPLY=()
+    while IFS=$'\t' read -r c _; do
+        printf -v c '%q' "$c"
+        COMPREPLY+=("$c")
+    done < <(command maybe complete -- "$1" ${2:+"$2"} 2>/dev/null)
+}
+
+__maybe_complete_jump() {
+    case $COMP_CWORD in
+    1) __maybe_folders "${COMP_WORDS[1]}" ;;
+    2) __maybe_folders "${COMP_WORDS[2]}" "${COMP_WORDS[1]}" ;;
+    *) COMPREPLY=() ;;
+    esac
+}
+
+complete -F __maybe_complete_jump {{.Cmd}} {{.ListCmd}}
+
+__maybe_complete() {
+    local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+    COMPREPLY=()
+    case $prev in
+{{- range .Flags}}{{bashCase (print "-" .Name) .}}{{end}}
+{{- range .Commands}}{{bashCase .Name .}}{{end}}
+    esac
+{{- with .FolderNames "|"}}
+    if (( COMP_CWORD > 2 )); then
+        case ${COMP_WORDS[COMP_CWORD-2]} in
+        {{.}}) __maybe_folders "$cur" "$prev"; return ;;
+        esac
+    fi
+{{- end}}
+    if [[ $cur == -* ]]; then
+        COMPREPLY=($(compgen -W '{{names .Flags "*" "-" " "}}' -- "$cur"))
+    elif (( COMP_CWORD == 1 )); then
+        COMPREPLY=($(compgen -W '{{names .Commands "*" "" " "}}' -- "$cur"))
+    fi
+}
+
+complete -F __maybe_complete maybe
+{{- end}}
+`
+
+const zsh = `# maybe shell integration for zsh
+# eval "$(maybe init zsh)"
+
+export MAYBE_SESSION=$$
+
+\builtin unalias {{.Cmd}} {{.ListCmd}} &>/dev/null || \builtin true
+
+{{.Cmd}}() {
+    if [[ $# -eq 0 ]]; then
+        builtin cd -- ~
+        return
+    fi
+    local d
+    # OLDPWD resolves -, it is not always exported
+    d=$(OLDPWD=$OLDPWD command maybe search -- "$@")
+    case $? in
+    0) ;;
+    # ambiguous or too weak, let the user pick
+    3) d=$(command maybe pick -- "$@") || return 3 ;;
+    *) return 2 ;;
+    esac
+    if [[ $d != "$PWD" ]]; then
+        builtin cd -- "$d"
+    fi
+}
+
+{{.ListCmd}}() {
+    command maybe list "$@"
+}
+{{- if .Hook}}
+
+__maybe_hook() {
+    command maybe add -- "$PWD"
+}
+
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd __maybe_hook
+{{- end}}
+{{- if .Completion}}
+
+# complete keywords by: maybe complete <partial> [start]
+__maybe_folders() {
+    local -a c
+    c=(${(f)"$(command maybe complete -- "$1" ${2:+"$2"} 2>/dev/null)"})
+    c=(${c//:/\\:})
+    c=(${c/$'\t'/:})
+    _describe -t folders 'folder' c
+}
+
+__maybe_complete_jump() {
+    case $CURRENT in
+    2) __maybe_folders "$PREFIX" ;;
+    3) __maybe_folders "$PREFIX" "${words[2]}" ;;
+    esac
+}
+
+__maybe_folders_arg() {
+    __maybe_folders "$PREFIX"
+}
+
+__maybe_rest() {
+    if (( CURRENT == 2 )); then
+        local -a c=(
+{{- range .Commands}}
+            {{zshQuote (print .Name ":" .Usage)}}
+{{- end}}
+        )
+        _describe -t commands 'command' c
+        return
+    fi
+    case ${words[CURRENT-1]} in
+{{- range .Commands}}
+    {{.Name}}) {{zshAction .}} ;;
+{{- end}}
+    *) __maybe_folders "$PREFIX" "${words[CURRENT-1]}" ;;
+    esac
+}
+
+_maybe() {
+    _arguments \
+{{- range .Flags}}
+        {{zshSpec .}} \
+{{- end}}
+        '*:keyword:__maybe_rest'
+}
+
+if (( $+functions[compdef] )); then
+    compdef __maybe_complete_jump {{.Cmd}} {{.ListCmd}}
+    compdef _maybe maybe
+fi
+{{- end}}
+`
+
+const fish = `# maybe shell integration for fish
+# maybe init fish | source
+
+set -gx MAYBE_SESSION $fish_pid
+
+function {{.Cmd}} --description 'jump to a known folder'
+    if test (count $argv) -eq 0
+        cd ~
+        return
+    end
+    # resolves -
+    set -lx OLDPWD $dirprev[-1]
+    set -l d (command maybe search -- $argv)
+    switch $status
+        case 0
+        case 3
+            # ambiguous or too weak, let the user pick
+            set d (command maybe pick -- $argv); or return 3
+        case '*'
+            return 2
+    end
+    if test "$d" != "$PWD"
+        cd $d
+    end
+end
+
+function {{.ListCmd}} --description 'list known folders'
+    command maybe list $argv
+end
+{{- if .Hook}}
+
+function __maybe_hook --on-variable PWD
+    command maybe add -- $PWD
+end
+{{- end}}
+{{- if .Completion}}
+
+# complete keywords by: maybe complete <partial> [start], the start
+# is the previous argument, if it is no flag and --no-start is not given
+function __maybe_folders
+    set -l args (commandline -opc)
+    set -e args[1]
+    set -l cur (commandline -ct)
+    set -l start
+    if test (count $args) -ge 1; and test "$argv[1]" != --no-start
+        and not string match -q -- '-*' $args[-1]
+        set start $args[-1]
+    end
+    command maybe complete -- "$cur" $start 2>/dev/null
+end
+
+complete -c {{.Cmd}} -f -n 'test (count (commandline -opc)) -le 2' -a '(__maybe_folders)'
+complete -c {{.ListCmd}} -f -n 'test (count (commandline -opc)) -le 2' -a '(__maybe_folders)'
+
+complete -c maybe -f
+{{- range .Flags}}
+complete -c maybe -o {{.Name}} -d {{fishQuote .Usage}}{{with fishArg .}} {{.}}{{end}}
+{{- end}}
+{{- range $c := .Commands}}
+complete -c maybe -n 'test (count (commandline -opc)) -eq 1' -a {{$c.Name}} -d {{fishQuote $c.Usage}}
+{{- with fishArg $c}}
+{{- if ne . "-x"}}
+complete -c maybe -n 'set -l a (commandline -opc); test (count $a) -eq 2; and test $a[2] = {{$c.Name}}' {{.}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- with .FolderNames " "}}
+complete -c maybe -n 'set -l a (commandline -opc); test (count $a) -ge 3; and contains -- $a[-2] {{.}}' -a '(__maybe_folders)'
+{{- end}}
+{{- end}}
+`
+
+const nushell = `# maybe shell integration for nushell 0.89 or newer
+# maybe init nushell | save -f ~/.maybe.nu
+# and add to config.nu: source ~/.maybe.nu
+
+$env.MAYBE_SESSION = ($nu.pid | into string)
+
+def --env {{.Cmd}} [...query: string] {
+    if ($query | is-empty) {
+        cd ~
+        return
+    }
+    let res = (^maybe search -- ...$query | complete)
+    let out = if $res.exit_code == 3 {
+        # ambiguous or too weak, let the user pick
+        ^maybe pick -- ...$query
+    } else if $res.exit_code != 0 {
+        error make --unspanned {msg: $"maybe: no result for ($query | str join ' ')"}
+    } else {
+        $res.stdout
+    }
+    let d = ($out | str trim --right --char "\n")
+    if $d != $env.PWD {
+        cd $d
+    }
+}
+
+def {{.ListCmd}} [...query: string] {
+    ^maybe list ...$query
+}
+{{- if .Hook}}
+
+$env.config = ($env.config? | default {}
+    | upsert hooks { default {} }
+    | upsert hooks.env_change { default {} }
+    | upsert hooks.env_change.PWD { default [] })
+$env.config.hooks.env_change.PWD = ($env.config.hooks.env_change.PWD
+    | append {|_, dir| ^maybe add -- $dir })
+{{- end}}
+`
+
+const elvish = `# maybe shell integration for elvish
+# eval (maybe init elvish | slurp)
+
+use str
+
+set E:MAYBE_SESSION = (to-string $pid)
+
+fn {{.Cmd}} {|@query|
+    if (== (count $query) 0) {
+        cd ~
+        return
+    }
+    var d = ''
+    var err = ?(set d = (e:maybe search -- $@query | slurp))
+    if (not $err) {
+        # ambiguous or too weak, let the user pick
+        if (!=s $err[reason][exit-status] 3) {
+            fail $err
+        }
+        set d = (e:maybe pick -- $@query | slurp)
+    }
+    set d = (str:trim-right $d "\n")
+    if (!=s $d $pwd) {
+        cd $d
+    }
+}
+
+fn {{.ListCmd}} {|@query|
+    e:maybe list $@query
+}
+{{- if .Hook}}
+
+set after-chdir = [$@after-chdir {|_| e:maybe add -- $pwd }]
+{{- end}}
+`
@@ -0,0 +1,202 @@
+// Package shell generates the shell integration of maybe.
+package shell
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Options of the generated integration.
+type Options struct {
+	Cmd     string // name of the jump function
+	ListCmd string // name of the list function
+	Hook    bool   // add the working directory to maybe on change
+	// Completion of the functions and of the Flags and Commands
+	// of maybe, for bash, zsh and fish
+	Completion bool
+	Flags      []Flag
+	Commands   []Flag
+}
+
+// DefaultOptions as used in the README.
+var DefaultOptions = Options{Cmd: "m", ListCmd: "mm", Hook: true,
+	Completion: true}
+
+// data of the script templates.
+type data struct {
+	Options
+	Shells []string
+}
+
+// FolderNames of the flags and commands with a keyword argument,
+// joined by sep.
+func (d data) FolderNames(sep string) string {
+	var a []string
+	for _, f := range d.Flags {
+		if f.Arg == ArgFolder {
+			a = append(a, "-"+f.Name)
+		}
+	}
+	for _, f := range d.Commands {
+		if f.Arg == ArgFolder {
+			a = append(a, f.Name)
+		}
+	}
+	return strings.Join(a, sep)
+}
+
+var funcs = template.FuncMap{
+	"join": func(a []string) string { return strings.Join(a, " ") },
+	// names of flags with argument kind arg, with prefix, joined by sep
+	"names": func(a []Flag, arg, prefix, sep string) string {
+		var names []string
+		for _, f := range a {
+			if f.Arg == arg || arg == "*" {
+				names = append(names, prefix+f.Name)
+			}
+		}
+		return strings.Join(names, sep)
+	},
+	"bashCase":  bashCase,
+	"fishArg":   fishArg,
+	"fishQuote": fishQuote,
+	"zshSpec":   zshSpec,
+	"zshAction": zshAction,
+	"zshQuote":  zshQuote,
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Names of the supported shells, sorted.
+func Names() []string {
+	var a []string
+	for k := range scripts {
+		a = append(a, k)
+	}
+	sort.Strings(a)
+	return a
+}
+
+// Init writes the integration script for shell sh to w.
+func Init(w io.Writer, sh string, o Options) error {
+	s, ok := scripts[sh]
+	if !ok {
+		return fmt.Errorf("unknown shell %q, supported: %v", sh, Names())
+	}
+	for _, name := range []string{o.Cmd, o.ListCmd} {
+		if !validName.MatchString(name) {
+			return fmt.Errorf("invalid command name %q", name)
+		}
+	}
+	if o.Cmd == o.ListCmd {
+		return fmt.Errorf("command names must differ, both are %q", o.Cmd)
+	}
+	for _, f := range append(o.Flags, o.Commands...) {
+		if !validName.MatchString(f.Name) {
+			return fmt.Errorf("invalid flag name %q", f.Name)
+		}
+		for _, v := range f.Values {
+			if !validName.MatchString(v) {
+				return fmt.Errorf("invalid value %q of flag %q", v, f.Name)
+			}
+		}
+	}
+	tpl, err := template.New(sh).Funcs(funcs).Parse(s)
+	if err != nil {
+		return fmt.Errorf("shell.Init - %v", err)
+	}
+	return tpl.Execute(w, data{Options: o, Shells: Names()})
+}
+
+// bashCase returns the case branch completing the argument of f,
+// if the previous word is pattern.
+func bashCase(pattern string, f Flag) string {
+	var action string
+	switch f.Arg {
+	case ArgNone:
+		return ""
+	case ArgAny:
+		action = "return"
+	case ArgDir:
+		action = `COMPREPLY=($(compgen -d -- "$cur")); return`
+	case ArgFile:
+		action = `COMPREPLY=($(compgen -f -- "$cur")); return`
+	case ArgFolder:
+		action = `__maybe_folders "$cur"; return`
+	case ArgValues:
+		action = `COMPREPLY=($(compgen -W '` + strings.Join(f.Values, " ") +
+			`' -- "$cur")); return`
+	}
+	return "\n    " + pattern + ") " + action + " ;;"
+}
+
+// fishArg returns the complete options of the argument of f.
+func fishArg(f Flag) string {
+	switch f.Arg {
+	case ArgAny:
+		return "-x"
+	case ArgDir:
+		return "-x -a '(__fish_complete_directories (commandline -ct))'"
+	case ArgFile:
+		return "-r -F"
+	case ArgFolder:
+		return "-x -a '(__maybe_folders --no-start)'"
+	case ArgValues:
+		return "-x -a '" + strings.Join(f.Values, " ") + "'"
+	}
+	return ""
+}
+
+// fishQuote s in single quotes.
+func fishQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + r.Replace(s) + "'"
+}
+
+// zshSpec of flag f, an _arguments option specification
+// in single quotes.
+func zshSpec(f Flag) string {
+	usage := f.Usage
+	if usage == "" {
+		usage = f.Name
+	}
+	r := strings.NewReplacer(`[`, `\[`, `]`, `\]`, `:`, `\:`, `'`, `'\''`)
+	s := "-" + f.Name + "[" + r.Replace(usage) + "]"
+	switch f.Arg {
+	case ArgAny:
+		s += ":" + f.Name + ": "
+	case ArgDir:
+		s += ":directory:_files -/"
+	case ArgFile:
+		s += ":file:_files"
+	case ArgFolder:
+		s += ":keyword:__maybe_folders_arg"
+	case ArgValues:
+		s += ":" + f.Name + ":(" + strings.Join(f.Values, " ") + ")"
+	}
+	return "'" + s + "'"
+}
+
+// zshAction completing the argument of f.
+func zshAction(f Flag) string {
+	switch f.Arg {
+	case ArgDir:
+		return "_files -/"
+	case ArgFile:
+		return "_files"
+	case ArgFolder:
+		return `__maybe_folders "$PREFIX"`
+	case ArgValues:
+		return "compadd " + strings.Join(f.Values, " ")
+	}
+	return ":"
+}
+
+// zshQuote s in single quotes.
+func zshQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
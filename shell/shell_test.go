@@ -0,0 +1,283 @@
+package shell
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInit(t *testing.T) {
+	// the search result is trimmed where the shell doesn't
+	trim := map[string]string{
+		"nushell": `str trim --right --char "\n"`,
+		"elvish":  `str:trim-right $d "\n"`,
+	}
+	for _, sh := range Names() {
+		t.Run(sh, func(t *testing.T) {
+			var b bytes.Buffer
+			o := Options{Cmd: "j", ListCmd: "jl", Hook: true}
+			if err := Init(&b, sh, o); err != nil {
+				t.Fatal(err)
+			}
+			s := b.String()
+			for _, exp := range []string{"MAYBE_SESSION", "j", "jl",
+				"maybe search", "maybe list", "maybe add", trim[sh]} {
+				if !strings.Contains(s, exp) {
+					t.Errorf("missing %q in:\n%s", exp, s)
+				}
+			}
+			b.Reset()
+			o.Hook = false
+			if err := Init(&b, sh, o); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(b.String(), "maybe add") {
+				t.Errorf("unexpected hook in:\n%s", b.String())
+			}
+		})
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	tt := []struct {
+		name, sh string
+		o        Options
+	}{
+		{name: "unknown shell", sh: "csh", o: DefaultOptions},
+		{name: "invalid name", sh: "bash",
+			o: Options{Cmd: "m;rm", ListCmd: "mm"}},
+		{name: "same names", sh: "bash", o: Options{Cmd: "m", ListCmd: "m"}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := Init(&b, tc.sh, tc.o); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// TestSyntax checks the scripts with the installed shells.
+func TestSyntax(t *testing.T) {
+	check := map[string]func(f string) []string{
+		"bash": func(f string) []string { return []string{"bash", "-n", f} },
+		"zsh":  func(f string) []string { return []string{"zsh", "-n", f} },
+		"fish": func(f string) []string {
+			return []string{"fish", "--no-execute", f}
+		},
+		// nu has no syntax check mode, sourcing only defines the
+		// functions and the hook
+		"nushell": func(f string) []string {
+			return []string{"nu", "--no-config-file", "-c", "source '" + f + "'"}
+		},
+		"elvish": func(f string) []string {
+			return []string{"elvish", "-compileonly", f}
+		},
+	}
+	for sh, cmd := range check {
+		t.Run(sh, func(t *testing.T) {
+			f := writeScript(t, sh)
+			args := cmd(f)
+			if _, err := exec.LookPath(args[0]); err != nil {
+				t.Skipf("%s not installed", args[0])
+			}
+			out, err := exec.Command(args[0], args[1:]...).
+				CombinedOutput()
+			if err != nil {
+				t.Errorf("%v: %s", err, out)
+			}
+		})
+	}
+}
+
+// TestBash runs the bash integration against a fake maybe,
+// which answers -search with a folder containing spaces, and
+// -pick if the search is ambiguous.
+func TestBash(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "a b")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fake := `#!/bin/sh
+echo "$@" >> "` + filepath.Join(dir, "log") + `"
+case "$1" in
+search) [ "$3" = "a b" ] && printf '%s' "` + target + `" && exit
+    [ "$3" = amb ] && exit 3 || exit 1;;
+pick) printf '%s' "` + target + `";;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "maybe"), []byte(fake),
+		0755); err != nil {
+		t.Fatal(err)
+	}
+	script := writeScript(t, "bash")
+	cmd := exec.Command("bash", "--norc", "-c", `source "$1"
+m 'a b' || exit 9
+__maybe_hook
+pwd
+m nothing
+echo "status $?"
+builtin cd /
+m amb
+pwd
+mm x y`, "bash", script)
+	cmd.Env = append(os.Environ(), "PATH="+dir+":"+os.Getenv("PATH"))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	exp := target + "\nstatus 2\n" + target + "\n"
+	if string(out) != exp {
+		t.Errorf("exp %q, got %q", exp, out)
+	}
+	log, err := os.ReadFile(filepath.Join(dir, "log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expLog := "search -- a b\nadd -- " + target + "\nsearch -- nothing\n" +
+		"search -- amb\npick -- amb\nlist x y\n"
+	if string(log) != expLog {
+		t.Errorf("exp log %q, got %q", expLog, log)
+	}
+}
+
+// TestJump runs the jump function of every shell against a fake
+// maybe, which prints the folder with a trailing newline.
+func TestJump(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "a b")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	fake := "#!/bin/sh\nprintf '%s\\n' \"" + target + "\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "maybe"), []byte(fake),
+		0755); err != nil {
+		t.Fatal(err)
+	}
+	tt := []struct {
+		sh, tail string
+		args     []string
+	}{
+		{sh: "bash", args: []string{"bash", "--norc"}, tail: "m 'a b'\npwd"},
+		{sh: "zsh", args: []string{"zsh", "-f"}, tail: "m 'a b'\npwd"},
+		{sh: "fish", args: []string{"fish", "--no-config"}, tail: "m 'a b'\npwd"},
+		{sh: "nushell", args: []string{"nu", "--no-config-file"},
+			tail: "m 'a b'\nprint $env.PWD"},
+		{sh: "elvish", args: []string{"elvish", "-norc"},
+			tail: "m 'a b'\necho $pwd"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.sh, func(t *testing.T) {
+			if _, err := exec.LookPath(tc.args[0]); err != nil {
+				t.Skipf("%s not installed", tc.args[0])
+			}
+			var b bytes.Buffer
+			o := Options{Cmd: "m", ListCmd: "mm"}
+			if err := Init(&b, tc.sh, o); err != nil {
+				t.Fatal(err)
+			}
+			b.WriteString("\n" + tc.tail + "\n")
+			f := filepath.Join(t.TempDir(), "jump."+tc.sh)
+			if err := os.WriteFile(f, b.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cmd := exec.Command(tc.args[0], append(tc.args[1:], f)...)
+			cmd.Env = append(os.Environ(), "PATH="+dir+":"+os.Getenv("PATH"))
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("%v: %s", err, out)
+			}
+			if exp := target + "\n"; string(out) != exp {
+				t.Errorf("exp %q, got %q", exp, out)
+			}
+		})
+	}
+}
+
+// TestBashCompletion runs the bash completion functions against
+// a fake maybe, which answers -complete with two candidates.
+func TestBashCompletion(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	dir := t.TempDir()
+	fake := `#!/bin/sh
+echo "$@" >> "` + filepath.Join(dir, "log") + `"
+printf 'a b\t9 /x/a b\nfoo\t5 /foo\n'
+`
+	if err := os.WriteFile(filepath.Join(dir, "maybe"), []byte(fake),
+		0755); err != nil {
+		t.Fatal(err)
+	}
+	o := DefaultOptions
+	o.Flags = []Flag{
+		{Name: "format", Usage: "output format", Arg: ArgValues,
+			Values: []string{"text", "json"}},
+		{Name: "search", Usage: "search", Arg: ArgFolder},
+		{Name: "v", Usage: "verbose"},
+	}
+	o.Commands = []Flag{
+		{Name: "search", Usage: "search", Arg: ArgFolder},
+		{Name: "version", Usage: "print version"},
+	}
+	script := writeScriptOptions(t, "bash", o)
+	cmd := exec.Command("bash", "--norc", "-c", `source "$1"
+show() { COMP_WORDS=("$@"); COMP_CWORD=$(( $# - 1 )); "$f"; echo "${COMPREPLY[*]}"; }
+f=__maybe_complete_jump
+show m a
+show m src a
+f=__maybe_complete
+show maybe -f
+show maybe -format j
+show maybe -search src ''
+show maybe ver
+show maybe search ''`, "bash", script)
+	cmd.Env = append(os.Environ(), "PATH="+dir+":"+os.Getenv("PATH"))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	exp := `a\ b foo
+a\ b foo
+-format
+json
+a\ b foo
+version
+a\ b foo
+`
+	if string(out) != exp {
+		t.Errorf("exp %q, got %q", exp, out)
+	}
+	log, err := os.ReadFile(filepath.Join(dir, "log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expLog := "complete -- a\ncomplete -- a src\ncomplete --  src\ncomplete -- \n"
+	if string(log) != expLog {
+		t.Errorf("exp log %q, got %q", expLog, log)
+	}
+}
+
+func writeScript(t *testing.T, sh string) string {
+	return writeScriptOptions(t, sh, DefaultOptions)
+}
+
+func writeScriptOptions(t *testing.T, sh string, o Options) string {
+	var b bytes.Buffer
+	if err := Init(&b, sh, o); err != nil {
+		t.Fatal(err)
+	}
+	f := filepath.Join(t.TempDir(), "init."+sh)
+	if err := os.WriteFile(f, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
@@ -0,0 +1,22 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package term
+
+import "errors"
+
+var errUnsupported = errors.New("terminal not supported on this platform")
+
+// IsTerminal returns true if fd refers to a terminal.
+func IsTerminal(fd uintptr) bool { return false }
+
+// Size returns the columns and rows of terminal fd.
+func Size(fd uintptr) (cols, rows int, err error) {
+	return 0, 0, errUnsupported
+}
+
+// MakeRaw puts terminal fd into raw mode. The returned
+// function restores the previous state.
+func MakeRaw(fd uintptr) (restore func() error, err error) {
+	return nil, errUnsupported
+}
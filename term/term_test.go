@@ -0,0 +1,25 @@
+package term
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestIsTerminal(t *testing.T) {
+	f, err := ioutil.TempFile("", "maybe.term_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if IsTerminal(f.Fd()) {
+		t.Fatal("regular file should not be a terminal")
+	}
+	if _, _, err := Size(f.Fd()); err == nil {
+		t.Fatal("regular file should have no terminal size")
+	}
+	if _, err := MakeRaw(f.Fd()); err == nil {
+		t.Fatal("regular file should not become raw")
+	}
+}
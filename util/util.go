@@ -3,19 +3,19 @@
 package util
 
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strconv"
 	"strings"
-	"unicode/utf8"
 
 	"github.com/thibran/maybe/pref"
+	"github.com/thibran/maybe/term"
 )
 
-const osSep = string(os.PathSeparator)
+const (
+	osSep            = string(os.PathSeparator)
+	defaultTermWidth = 80
+)
 
 // Logf prints to stdout if pref.Verbose is true.
 func Logf(format string, a ...interface{}) {
@@ -31,57 +31,171 @@ func Logln(a ...interface{}) {
 	}
 }
 
-// ShortenPath when necessary, tries to keep the last two segments intact.
+// ShortenPath to max terminal columns, when necessary.
+// Tries to keep the last two segments intact.
 func ShortenPath(p string, max int) string {
-	rlen := utf8.RuneCountInString
-	{
-		pathLen := rlen(p)
-		// shorter than max
-		if pathLen == 0 || pathLen <= max {
-			return p
-		}
+	s, _ := ShortenPathTail(p, max, 2)
+	return s
+}
+
+// ShortenPathTail to max terminal columns, when necessary. Steps, until
+// the path fits: the home directory is replaced by ~, middle segments
+// are abbreviated to their first letter, then replaced by ..., at last
+// the start of the tail is cut. The tail are the last n segments.
+// For every segment of the result, short reports if it was abbreviated
+// or replaced by ...; a cut first segment starts with ... instead.
+func ShortenPathTail(p string, max, n int) (s string, short []bool) {
+	if StringWidth(p) <= max {
+		return p, nil
+	}
+	p = Tilde(p)
+	if StringWidth(p) <= max {
+		return p, nil
 	}
-	// handle last segment too long
 	a := strings.Split(p, osSep)
-	last := filepath.Join(a[len(a)-2:]...)
-	lastLen := rlen(last)
-	if lastLen > max {
-		last = "..." + last[lastLen+3-max:]
-		return last
-	}
-	// try to keep start, replace mid path segments
-	res := osSep
-	// keep space for seperators
-	max = max - (len(a) - 2)
-	dotWithSepLen := 4
-	for _, v := range a[:len(a)-2] {
-		if rlen(res)+rlen(v)+lastLen+dotWithSepLen > max {
-			break
+	if n < 1 {
+		n = 1
+	}
+	if len(a) < n+1 {
+		return cut(p, max), nil
+	}
+	short = make([]bool, len(a))
+	fits := func() (string, bool) {
+		s := strings.Join(a, osSep)
+		return s, StringWidth(s) <= max
+	}
+	// abbreviate mid segments, from left to right
+	for i := 1; i < len(a)-n; i++ {
+		a[i] = abbreviate(a[i])
+		short[i] = true
+		if s, ok := fits(); ok {
+			return s, short
+		}
+	}
+	// replace mid segments by ..., from left to right
+	if len(a) > n+1 {
+		a[1] = shortened
+		for {
+			if s, ok := fits(); ok {
+				return s, short
+			}
+			if len(a) == n+2 {
+				break
+			}
+			a = append(a[:2], a[3:]...)
+			short = append(short[:2], short[3:]...)
 		}
-		res = filepath.Join(res, v)
 	}
-	return filepath.Join(res, "...", last)
+	return cut(strings.Join(a[len(a)-n:], osSep), max), nil
 }
 
-// TermWidth returns the terminal with.
-func TermWidth() (int, error) {
-	cmd := exec.Command("tput", "cols")
-	buf, err := cmd.Output()
-	if err != nil {
-		return 0, err
+// UniqueSuffixes returns for every path the number of trailing
+// segments needed to tell it apart from all other paths.
+// Equal paths get their segment count.
+func UniqueSuffixes(paths []string) []int {
+	segs := make([][]string, len(paths))
+	res := make([]int, len(paths))
+	open := len(paths)
+	for i, p := range paths {
+		segs[i] = strings.Split(p, osSep)
 	}
-	buf = bytes.TrimSpace(buf)
-	width, err := strconv.Atoi(string(buf))
-	if err != nil {
-		return 0, err
+	for n := 1; open > 0; n++ {
+		count := make(map[string]int, open)
+		for _, a := range segs {
+			count[suffixOf(a, n)]++
+		}
+		for i, a := range segs {
+			if res[i] != 0 {
+				continue
+			}
+			if count[suffixOf(a, n)] == 1 || n == len(a) {
+				res[i] = n
+				open--
+			}
+		}
 	}
-	return width, nil
+	return res
 }
 
-// NormalOrVerbose returns the normal string if Pref.Verbose is not true.
-func NormalOrVerbose(normal, verb string) string {
-	if !pref.Verbose {
-		return normal
+// Suffix returns the last n segments of path p.
+func Suffix(p string, n int) string {
+	return suffixOf(strings.Split(p, osSep), n)
+}
+
+func suffixOf(a []string, n int) string {
+	if n > len(a) {
+		n = len(a)
+	}
+	return strings.Join(a[len(a)-n:], osSep)
+}
+
+// shortened marks removed path segments.
+const shortened = "..."
+
+// abbreviate path segment s to its first letter,
+// a leading dot is kept.
+func abbreviate(s string) string {
+	rs := []rune(s)
+	if len(rs) > 1 && rs[0] == '.' {
+		return string(rs[:2])
+	}
+	if len(rs) > 0 && s != shortened {
+		return string(rs[:1])
+	}
+	return s
+}
+
+// cut the start of s and prefix it with ..., to fit into max columns.
+func cut(s string, max int) string {
+	if max <= len(shortened) {
+		return cutLeft(s, max)
+	}
+	return shortened + cutLeft(s, max-len(shortened))
+}
+
+// Tilde replaces the home directory prefix of path p with ~.
+func Tilde(p string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" || home == osSep {
+		return p
+	}
+	home = strings.TrimSuffix(home, osSep)
+	if p == home {
+		return "~"
+	}
+	if strings.HasPrefix(p, home+osSep) {
+		return "~" + strings.TrimPrefix(p, home)
+	}
+	return p
+}
+
+// ExpandTilde replaces a leading ~ of path p with the home directory,
+// the reverse of Tilde.
+func ExpandTilde(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~"+osSep) {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return p
+	}
+	return strings.TrimSuffix(home, osSep) + p[1:]
+}
+
+// TermWidth returns the terminal width: pref.Width if set, else the
+// width of the terminal on stdout or stderr, else $COLUMNS,
+// else defaultTermWidth.
+func TermWidth() int {
+	if pref.Width > 0 {
+		return pref.Width
+	}
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if cols, _, err := term.Size(f.Fd()); err == nil && cols > 0 {
+			return cols
+		}
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
 	}
-	return verb
+	return defaultTermWidth
 }
@@ -1,11 +1,19 @@
 package util
 
 import (
+	"fmt"
+	"os"
 	"testing"
 	"unicode/utf8"
+
+	"github.com/thibran/maybe/pref"
+	"github.com/thibran/maybe/term"
 )
 
 func TestShortenPath(t *testing.T) {
+	home := os.Getenv("HOME")
+	defer os.Setenv("HOME", home)
+	os.Setenv("HOME", "/nonexistent")
 	tt := []struct {
 		name, path, exp string
 		maxlen          int
@@ -18,14 +26,40 @@ func TestShortenPath(t *testing.T) {
 			path: "/home/tux/src/other/lo-design/dark_with_sidebar"},
 		{name: "cut a bit", maxlen: 50,
 			path: "/home/tux/src/other/lo-design/dark_with_sidebar"},
+		{name: "abbreviate", maxlen: 36,
+			path: "/home/tux/src/other/lo-design/dark_with_sidebar",
+			exp:  "/h/t/s/o/lo-design/dark_with_sidebar"},
+		{name: "abbreviate some", maxlen: 40,
+			path: "/home/tux/src/other/lo-design/dark_with_sidebar",
+			exp:  "/h/t/s/other/lo-design/dark_with_sidebar"},
+		{name: "hidden segment", maxlen: 24,
+			path: "/home/.config/fish/functions",
+			exp:  "/h/.c/fish/functions"},
+		{name: "replace mid", maxlen: 34,
+			path: "/home/tux/src/other/lo-design/dark_with_sidebar",
+			exp:  "/.../o/lo-design/dark_with_sidebar"},
+		{name: "cut tail", maxlen: 30,
+			path: "/home/tux/src/other/lo-design/dark_with_sidebar",
+			exp:  "...lo-design/dark_with_sidebar"},
+		{name: "multibyte", maxlen: 10,
+			path: "/home/tux/über/äöüäöüäöü",
+			exp:  "...üäöüäöü"},
+		{name: "wide runes", maxlen: 15,
+			path: "/home/tux/日本語/日本語日本語",
+			exp:  "...日本語日本語"},
+		{name: "single segment", maxlen: 8,
+			path: "/verylongname",
+			exp:  "...gname"},
 	}
-	rlen := utf8.RuneCountInString
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			res := ShortenPath(tc.path, tc.maxlen)
-			if rlen(res) > tc.maxlen {
+			if !utf8.ValidString(res) {
+				t.Fatalf("invalid utf-8: %q", res)
+			}
+			if w := StringWidth(res); w > tc.maxlen {
 				t.Fatalf("should be not longer than %d, but is %d",
-					tc.maxlen, rlen(res))
+					tc.maxlen, w)
 			}
 			if tc.exp != "" && tc.exp != res {
 				t.Fatalf("exp %q, got %q", tc.exp, res)
@@ -33,3 +67,121 @@ func TestShortenPath(t *testing.T) {
 		})
 	}
 }
+
+func TestTermWidth(t *testing.T) {
+	pref.Width = 33
+	if w := TermWidth(); w != 33 {
+		t.Fatalf("exp override 33, got %d", w)
+	}
+	pref.Width = 0
+	if _, _, err := term.Size(os.Stdout.Fd()); err == nil {
+		t.Skip("stdout is a terminal")
+	}
+	if _, _, err := term.Size(os.Stderr.Fd()); err == nil {
+		t.Skip("stderr is a terminal")
+	}
+	columns := os.Getenv("COLUMNS")
+	defer os.Setenv("COLUMNS", columns)
+	os.Setenv("COLUMNS", "123")
+	if w := TermWidth(); w != 123 {
+		t.Fatalf("exp $COLUMNS 123, got %d", w)
+	}
+	os.Setenv("COLUMNS", "")
+	if w := TermWidth(); w != defaultTermWidth {
+		t.Fatalf("exp default %d, got %d", defaultTermWidth, w)
+	}
+}
+
+func TestTilde(t *testing.T) {
+	home := os.Getenv("HOME")
+	defer os.Setenv("HOME", home)
+	os.Setenv("HOME", "/home/tux")
+	tt := []struct {
+		path, exp string
+	}{
+		{path: "/home/tux", exp: "~"},
+		{path: "/home/tux/src", exp: "~/src"},
+		{path: "/home/tuxedo", exp: "/home/tuxedo"},
+		{path: "/tmp", exp: "/tmp"},
+	}
+	for _, tc := range tt {
+		if s := Tilde(tc.path); s != tc.exp {
+			t.Errorf("exp %q, got %q", tc.exp, s)
+		}
+	}
+	if s := ShortenPath("/home/tux/src/github.com/foo", 20); s != "~/src/github.com/foo" {
+		t.Errorf("home should be abbreviated, got %q", s)
+	}
+}
+
+func TestUniqueSuffixes(t *testing.T) {
+	paths := []string{
+		"/srv/svc1/api",
+		"/srv/svc2/api",
+		"/home/tux/web",
+		"/a/api",
+		"/b/a/api",
+		"/tmp",
+		"/tmp",
+	}
+	exp := []int{2, 2, 1, 3, 3, 2, 2}
+	res := UniqueSuffixes(paths)
+	for i, n := range res {
+		if n != exp[i] {
+			t.Errorf("%s - exp %d, got %d", paths[i], exp[i], n)
+		}
+	}
+	if s := Suffix(paths[3], res[3]); s != "/a/api" {
+		t.Errorf("exp %q, got %q", "/a/api", s)
+	}
+	if s := Suffix(paths[0], res[0]); s != "svc1/api" {
+		t.Errorf("exp %q, got %q", "svc1/api", s)
+	}
+}
+
+func TestExpandTilde(t *testing.T) {
+	home := os.Getenv("HOME")
+	defer os.Setenv("HOME", home)
+	os.Setenv("HOME", "/home/tux/")
+	tt := []struct {
+		p, exp string
+	}{
+		{p: "~", exp: "/home/tux"},
+		{p: "~/src", exp: "/home/tux/src"},
+		{p: "~tux/src", exp: "~tux/src"},
+		{p: "/tmp/~", exp: "/tmp/~"},
+	}
+	for _, tc := range tt {
+		if res := ExpandTilde(tc.p); res != tc.exp {
+			t.Errorf("exp %q, got %q", tc.exp, res)
+		}
+	}
+}
+
+func TestShortenPathTail(t *testing.T) {
+	home := os.Getenv("HOME")
+	defer os.Setenv("HOME", home)
+	os.Setenv("HOME", "/nonexistent")
+	p := "/home/tux/src/svc1/api/src"
+	if s := ShortenPath(p, 16); s != "/h/t/s/s/api/src" {
+		t.Errorf("exp %q, got %q", "/h/t/s/s/api/src", s)
+	}
+	s, short := ShortenPathTail(p, 16, 3)
+	if s != "...svc1/api/src" || short != nil {
+		t.Errorf("exp %q, got %q %v", "...svc1/api/src", s, short)
+	}
+	s, short = ShortenPathTail(p, 20, 2)
+	if s != "/h/t/s/svc1/api/src" {
+		t.Errorf("exp %q, got %q", "/h/t/s/svc1/api/src", s)
+	}
+	if res := fmt.Sprint(short); res != "[false true true true false false false]" {
+		t.Errorf("unexpected shortened segments %s", res)
+	}
+	s, short = ShortenPathTail("/home/tux/src/svc1/api", 14, 2)
+	if s != "/.../svc1/api" {
+		t.Errorf("exp %q, got %q", "/.../svc1/api", s)
+	}
+	if res := fmt.Sprint(short); res != "[false true false false]" {
+		t.Errorf("unexpected shortened segments %s", res)
+	}
+}
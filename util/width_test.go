@@ -0,0 +1,41 @@
+package util
+
+import "testing"
+
+func TestStringWidth(t *testing.T) {
+	tt := []struct {
+		name, s string
+		exp     int
+	}{
+		{name: "ascii", s: "maybe", exp: 5},
+		{name: "umlaut", s: "grün", exp: 4},
+		{name: "combining mark", s: "grün", exp: 4},
+		{name: "cjk", s: "日本語", exp: 6},
+		{name: "hangul", s: "한국어", exp: 6},
+		{name: "emoji", s: "🚀x", exp: 3},
+		{name: "variation selector", s: "❤️", exp: 1},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if n := StringWidth(tc.s); n != tc.exp {
+				t.Errorf("exp %d, got %d", tc.exp, n)
+			}
+		})
+	}
+}
+
+func TestCutLeft(t *testing.T) {
+	tt := []struct {
+		s, exp string
+		max    int
+	}{
+		{s: "abcdef", max: 3, exp: "def"},
+		{s: "日本語", max: 5, exp: "本語"},
+		{s: "日本語", max: 1, exp: ""},
+	}
+	for _, tc := range tt {
+		if res := cutLeft(tc.s, tc.max); res != tc.exp {
+			t.Errorf("exp %q, got %q", tc.exp, res)
+		}
+	}
+}